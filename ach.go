@@ -0,0 +1,142 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+
+	"github.com/emer/etable/metric"
+	"github.com/emer/etable/minmax"
+	"github.com/emer/leabra/leabra"
+)
+
+// AChParams control an acetylcholine-like neuromodulatory state (Hasselmo-style
+// encoding / retrieval modes), driven by novelty: high ACh (novel, encoding mode)
+// weakens the ECout -> ECin loop and the CA3 recurrent collaterals, and raises
+// the learning rate; low ACh (familiar, retrieval mode) does the opposite.
+// Signal is one of:
+//
+//	ECMismatch: 1 - cosine between ECout and ECin Act, at the update point
+//	CA3ED:      TrlEDCA3 of the previous trial
+//	CosDiff:    1 - ECout CosDiff of the previous trial
+type AChParams struct {
+	On        bool       `desc:"use the ACh state to modulate projection scales and learning rates"`
+	Signal    string     `desc:"novelty signal driving ACh: ECMismatch, CA3ED, or CosDiff"`
+	Range     minmax.F32 `desc:"range of the novelty signal mapped onto ACh 0..1 (clipped)"`
+	Dt        float32    `min:"0" max:"1" desc:"rate at which ACh moves toward the level set by the signal at each update (1 = immediately)"`
+	UpdtPhase int        `desc:"theta phase after which ACh is updated (0 = end of Q1) -- later phases of the trial are modulated by the new level -- -1 = update at the start of the trial"`
+	LoopMod   float32    `desc:"ECoutToECin WtScale.Rel is multiplied by 1 - LoopMod * ACh"`
+	RecMod    float32    `desc:"CA3ToCA3 WtScale.Rel is multiplied by 1 - RecMod * ACh"`
+	LrateMin  float32    `desc:"learning rate multiplier at ACh = 0, rising linearly to 1 at ACh = 1 -- combined with any ErrLrMod multiplier"`
+}
+
+func (ap *AChParams) Defaults() {
+	ap.Signal = "ECMismatch"
+	ap.Range.Set(0.1, 0.6)
+	ap.Dt = 1
+	ap.UpdtPhase = 1
+	ap.LoopMod = 0.5
+	ap.RecMod = 0.5
+	ap.LrateMin = 0.5
+}
+
+func (ap *AChParams) Update() {
+	switch ap.Signal {
+	case "ECMismatch", "CA3ED", "CosDiff":
+	default:
+		log.Printf("AChParams: unknown Signal: %s -- using ECMismatch\n", ap.Signal)
+		ap.Signal = "ECMismatch"
+	}
+}
+
+// AChFmSignal returns the ACh level set by given novelty signal value
+func (ap *AChParams) AChFmSignal(sig float32) float32 {
+	switch {
+	case sig <= ap.Range.Min:
+		return 0
+	case sig >= ap.Range.Max:
+		return 1
+	}
+	return ap.Range.NormVal(sig)
+}
+
+// LrateMult returns the learning rate multiplier for given ACh level
+func (ap *AChParams) LrateMult(ach float32) float32 {
+	return ap.LrateMin + ach*(1-ap.LrateMin)
+}
+
+// AChSignal returns the current value of the novelty signal
+func (ss *Sim) AChSignal() float32 {
+	switch ss.ACh.Signal {
+	case "CA3ED":
+		return ss.TrlEDCA3
+	case "CosDiff":
+		return float32(1 - ss.TrlCosDiff)
+	}
+	ecin := ss.Net.LayerByName("ECin").(leabra.LeabraLayer).AsLeabra()
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	var out []float32
+	ecin.UnitVals(&ss.TmpVals, "Act")
+	ecout.UnitVals(&out, "Act")
+	return 1 - metric.Cosine32(ss.TmpVals, out)
+}
+
+// AChUpdt updates the ACh state from the novelty signal
+func (ss *Sim) AChUpdt() {
+	trg := ss.ACh.AChFmSignal(ss.AChSignal())
+	ss.TrlACh += ss.ACh.Dt * (trg - ss.TrlACh)
+}
+
+// AChApply sets the modulated projection scales for the current ACh level,
+// relative to the base values recorded by AChStart
+func (ss *Sim) AChApply() {
+	if pj := ss.PrjnByName("ECoutToECin"); pj != nil {
+		pj.WtScale.Rel = ss.AChRel[0] * (1 - ss.ACh.LoopMod*ss.TrlACh)
+	}
+	if pj := ss.PrjnByName("CA3ToCA3"); pj != nil {
+		pj.WtScale.Rel = ss.AChRel[1] * (1 - ss.ACh.RecMod*ss.TrlACh)
+	}
+}
+
+// AChStart is called at the start of AlphaCyc: it records the base scales of the
+// modulated projections and applies the current ACh level (updated first if
+// UpdtPhase < 0), returning a function that restores the base scales.
+// Projections missing from the network are skipped.
+func (ss *Sim) AChStart() func() {
+	if !ss.ACh.On {
+		return func() {}
+	}
+	loop := ss.PrjnByName("ECoutToECin")
+	rec := ss.PrjnByName("CA3ToCA3")
+	if loop != nil {
+		ss.AChRel[0] = loop.WtScale.Rel
+	}
+	if rec != nil {
+		ss.AChRel[1] = rec.WtScale.Rel
+	}
+	if ss.ACh.UpdtPhase < 0 {
+		ss.AChUpdt()
+	}
+	ss.AChApply()
+	return func() {
+		if loop != nil {
+			loop.WtScale.Rel = ss.AChRel[0]
+		}
+		if rec != nil {
+			rec.WtScale.Rel = ss.AChRel[1]
+		}
+	}
+}
+
+// AChPhase updates and applies the ACh level if theta phase pi has just
+// ended and is the UpdtPhase -- returns true if the scales changed
+func (ss *Sim) AChPhase(pi int) bool {
+	if !ss.ACh.On || pi != ss.ACh.UpdtPhase {
+		return false
+	}
+	ss.AChUpdt()
+	ss.AChApply()
+	return true
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+)
+
+// ArchVariant is a named architecture variant, selecting the learning rule
+// for each hippocampal pathway.  Its params are in the "CA3CA1_<CA3CA1>"
+// ParamSet, for params specific to the CA3 -> CA1 rule, and the "Arch_<Name>"
+// ParamSet (if any), applied in that order after Base and before the ParamSet.
+type ArchVariant struct {
+	Name   string `desc:"name of the variant, as given to -arch"`
+	Desc   string `desc:"description of the variant"`
+	PPath  string `desc:"rule for the perforant path to CA3 and the CA3 recurrents: EcCa1 (error-driven EcCa1Prjn, class PPath) or CHL (CHLPrjn, class HippoCHL)"`
+	CA3CA1 string `desc:"rule for CA3 -> CA1: CHL (CHLPrjn, class HippoCHL) or XCal (default leabra projection)"`
+}
+
+// ArchVariants are the available architecture variants -- the first is the default
+var ArchVariants = []ArchVariant{
+	{Name: "Std", Desc: "error-driven EcCa1Prjn perforant path and CA3 recurrents, CHL CA3 -> CA1", PPath: "EcCa1", CA3CA1: "CHL"},
+	{Name: "CHLPPath", Desc: "CHL perforant path and CA3 recurrents -- so far sig worse, even with error-driven MinusQ1", PPath: "CHL", CA3CA1: "CHL"},
+	{Name: "XCalCA3CA1", Desc: "default leabra XCal CA3 -> CA1 -- needs lrate ~1, doesn't work nearly as well", PPath: "EcCa1", CA3CA1: "XCal"},
+	{Name: "BCM", Desc: "BCM-only (no error-driven) learning on the perforant path and CA3 recurrents, via XCal.SetLLrn", PPath: "EcCa1", CA3CA1: "CHL"},
+}
+
+// ArchByName returns the architecture variant of given name, or nil if not found
+func ArchByName(nm string) *ArchVariant {
+	for i := range ArchVariants {
+		if ArchVariants[i].Name == nm {
+			return &ArchVariants[i]
+		}
+	}
+	return nil
+}
+
+// ArchVar returns the currently selected architecture variant (Arch),
+// reverting to the default if it is not a known variant
+func (ss *Sim) ArchVar() *ArchVariant {
+	if av := ArchByName(ss.Arch); av != nil {
+		return av
+	}
+	log.Printf("unknown architecture variant: %s -- using %s\n", ss.Arch, ArchVariants[0].Name)
+	ss.Arch = ArchVariants[0].Name
+	return &ArchVariants[0]
+}
+
+// ArchParamSets returns the names of the existing ParamSets for the current
+// variant: CA3CA1_<CA3CA1> and Arch_<Name>
+func (ss *Sim) ArchParamSets() []string {
+	av := ss.ArchVar()
+	var nms []string
+	for _, nm := range []string{"CA3CA1_" + av.CA3CA1, "Arch_" + av.Name} {
+		if _, err := ss.Params.SetByNameTry(nm); err == nil {
+			nms = append(nms, nm)
+		}
+	}
+	return nms
+}
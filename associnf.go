@@ -0,0 +1,225 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// AssocInfParams control the associative inference paradigm: the last BCEpcs
+// study sessions are B-C pairs (TrainAC, with B as the cue) chained onto the
+// A-B pairs of the earlier sessions, with the first B-C session starting Lag
+// drift steps after the last A-B session.  Each test then probes the
+// transitive A -> C association directly (TestAC), along with A-B and B-C,
+// and the reactivation of B by the A cue in each of the Layers.
+type AssocInfParams struct {
+	On     bool     `desc:"use the associative inference paradigm (command line)"`
+	Lag    int      `min:"1" desc:"drift steps between the end of the last A-B session and the first B-C session"`
+	BCEpcs int      `min:"1" desc:"number of B-C sessions, at the end of the MaxEpcs sessions"`
+	Layers []string `desc:"layers in which B reactivation by the A cue is measured"`
+}
+
+func (ap *AssocInfParams) Defaults() {
+	ap.BCEpcs = 1
+	ap.Layers = []string{"CA3", "CA1", "ECout"}
+}
+
+func (ap *AssocInfParams) Update() {
+	if ap.Lag < 1 {
+		ap.Lag = 1
+	}
+	if ap.BCEpcs < 1 {
+		ap.BCEpcs = 1
+	}
+}
+
+// AssocInfABEpcs returns the number of A-B sessions, before the B-C sessions
+func (ss *Sim) AssocInfABEpcs() int {
+	nab := ss.MaxEpcs - ss.AssocInf.BCEpcs
+	if nab < 1 {
+		log.Printf("AssocInf: need at least one A-B session -- MaxEpcs: %d, BCEpcs: %d\n", ss.MaxEpcs, ss.AssocInf.BCEpcs)
+		nab = 1
+	}
+	return nab
+}
+
+// AssocInfCtxts regenerates the session contexts of the B-C sessions for
+// context pool i, so that the first starts Lag drift steps after the end of
+// the last A-B session, with given between- and within-session drift rates.
+// Called from ConfigPats after the session contexts are made, so the test
+// contexts follow on from the last B-C session.  Sessions past the fillers
+// keep their usual contexts.
+func (ss *Sim) AssocInfCtxts(i int, drv, drvL float32) {
+	nab := ss.AssocInfABEpcs()
+	if nab-1 >= len(ss.fillers) {
+		if i == 0 {
+			log.Printf("AssocInf: no gap after A-B session %d to set Lag -- max sessions: %d\n", nab, len(ss.fillers)+1)
+		}
+		return
+	}
+	ss.fillers[nab-1] = ss.AssocInf.Lag
+	ss.ChainSessionCtxts(i, nab, drv, drvL)
+}
+
+// ConfigAssocInfPats makes the chained patterns: TrainAC is B-C in the first
+// B-C session context, TestAC is A -> C, and TestBC is B -> C, both in the
+// test context -- called at the end of ConfigPats
+func (ss *Sim) ConfigAssocInfPats() {
+	ss.TrainAC = ss.SessionPats("TrainAC", "B", "C", ss.AssocInfABEpcs())
+	ss.TestPats(ss.TestAC, "TestAC", "A", "C")
+	ss.TestPats(ss.TestBC, "TestBC", "B", "C")
+}
+
+// AssocInfSession sets the training patterns for the B-C sessions -- called
+// at the start of each session (epoch), after the A-B table is set
+func (ss *Sim) AssocInfSession(epc int) {
+	nab := ss.AssocInfABEpcs()
+	switch {
+	case epc == nab:
+		ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAC)
+	case epc > nab && epc < ss.MaxEpcs:
+		ss.TrainEnv.Table = etable.NewIdxView(ss.SessionPats(fmt.Sprintf("TrainBC%d", epc-nab+1), "B", "C", epc))
+	default:
+		return
+	}
+	ss.TrainEnv.SetTrialName()
+	ss.TrainEnv.SetGroupName()
+}
+
+// AssocInfActs returns the ActM of each of the AssocInf.Layers
+func (ss *Sim) AssocInfActs() [][]float32 {
+	acts := make([][]float32, len(ss.AssocInf.Layers))
+	for li, lnm := range ss.AssocInf.Layers {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		ly.UnitVals(&acts[li], "ActM")
+	}
+	return acts
+}
+
+// AssocInfTest tests A -> B, B -> C and the transitive A -> C, and the
+// reactivation of B by the A cue in each layer: the mean cosine between the
+// A-cued and B-cued activity of the same item, minus that of different items.
+// Logs the results to AssocInfLog.
+func (ss *Sim) AssocInfTest() {
+	mem := make(map[string]float64)
+	var aacts, bacts [][][]float32
+	for _, tst := range []struct {
+		nm   string
+		dt   *etable.Table
+		acts *[][][]float32
+	}{{"AB", ss.TestAB, nil}, {"BC", ss.TestBC, &bacts}, {"AC", ss.TestAC, &aacts}} {
+		n := 0.0
+		ss.TestTable(tst.dt, func() {
+			mem[tst.nm] += ss.Mem
+			n++
+			if tst.acts != nil {
+				*tst.acts = append(*tst.acts, ss.AssocInfActs())
+			}
+		})
+		if n > 0 {
+			mem[tst.nm] /= n
+		}
+	}
+
+	react := make([]float64, len(ss.AssocInf.Layers))
+	for li := range ss.AssocInf.Layers {
+		same, diff := 0.0, 0.0
+		ns, nd := 0, 0
+		for ai := range aacts {
+			for bi := range bacts {
+				cos := float64(metric.Cosine32(aacts[ai][li], bacts[bi][li]))
+				if ai == bi {
+					same += cos
+					ns++
+				} else {
+					diff += cos
+					nd++
+				}
+			}
+		}
+		if ns > 0 {
+			react[li] = same / float64(ns)
+		}
+		if nd > 0 {
+			react[li] -= diff / float64(nd)
+		}
+	}
+	ss.LogAssocInf(ss.AssocInfLog, mem, react)
+}
+
+//////////////////////////////////////////////
+//  AssocInfLog
+
+// LogAssocInf adds the results of one associative inference test to the AssocInfLog
+func (ss *Sim) LogAssocInf(dt *etable.Table, mem map[string]float64, react []float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellFloat("Lag", row, float64(ss.AssocInf.Lag))
+	for _, tst := range []string{"AB", "BC", "AC"} {
+		dt.SetCellFloat(tst+" Mem", row, mem[tst])
+	}
+	for li, lnm := range ss.AssocInf.Layers {
+		dt.SetCellFloat(lnm+" BReact", row, react[li])
+	}
+
+	if ss.AssocInfPlot != nil {
+		ss.AssocInfPlot.GoUpdate()
+	}
+	if ss.AssocInfFile != nil {
+		if !ss.AssocInfHdrs {
+			dt.WriteCSVHeaders(ss.AssocInfFile, etable.Tab)
+			ss.AssocInfHdrs = true
+		}
+		dt.WriteCSVRow(ss.AssocInfFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigAssocInfLog(dt *etable.Table) {
+	dt.SetMetaData("name", "AssocInfLog")
+	dt.SetMetaData("desc", "Associative inference: A-B, B-C and transitive A-C memory, and B reactivation by the A cue per layer")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Lag", etensor.INT64, nil, nil},
+		{"AB Mem", etensor.FLOAT64, nil, nil},
+		{"BC Mem", etensor.FLOAT64, nil, nil},
+		{"AC Mem", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.AssocInf.Layers {
+		sch = append(sch, etable.Column{lnm + " BReact", etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigAssocInfPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Associative Inference Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Lag", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AB Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("BC Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("AC Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	for _, lnm := range ss.AssocInf.Layers {
+		plt.SetColParams(lnm+" BReact", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	}
+	return plt
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// BOLDParams control the synthetic BOLD analysis: the per-trial Signal of
+// each of the LayStatNms layers is recorded along the whole timeline of the
+// run, in drift steps of StepSecs each -- study trials, filler trials (see
+// Filler params) and every test, with no activity over the rest of the
+// gaps.  The tests take no drift time, so each one is placed at the end of
+// the gap after the session it follows, and delays all later trials.  At the end of the run it is convolved with a canonical double-gamma
+// HRF and sampled every TR, giving one BOLD time series per layer.
+type BOLDParams struct {
+	On       bool    `desc:"record the layer signals and make synthetic BOLD time series at the end of each run (command line)"`
+	Signal   string  `desc:"per-trial signal: Act (mean minus phase activity, ActM.Avg) or Ge (total net input)"`
+	TR       float64 `min:"0" desc:"repetition time of the BOLD samples, in seconds"`
+	StepSecs float64 `min:"0" desc:"duration of one drift step (trial), in seconds"`
+	Peak     float64 `desc:"HRF: shape of the response gamma, which peaks at Peak-1 seconds"`
+	Under    float64 `desc:"HRF: shape of the undershoot gamma"`
+	Ratio    float64 `desc:"HRF: ratio of response to undershoot"`
+	Length   float64 `desc:"HRF: length of the kernel, in seconds"`
+}
+
+func (bp *BOLDParams) Defaults() {
+	bp.Signal = "Act"
+	bp.TR = 2
+	bp.StepSecs = 4
+	bp.Peak = 6
+	bp.Under = 16
+	bp.Ratio = 6
+	bp.Length = 32
+}
+
+func (bp *BOLDParams) Update() {
+	switch bp.Signal {
+	case "Act", "Ge":
+	default:
+		log.Printf("BOLDParams: unknown Signal: %s -- using Act\n", bp.Signal)
+		bp.Signal = "Act"
+	}
+}
+
+// HRF returns the canonical double-gamma hemodynamic response at time t secs
+func (bp *BOLDParams) HRF(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	gam := func(a float64) float64 {
+		lg, _ := math.Lgamma(a)
+		return math.Exp((a-1)*math.Log(t) - t - lg)
+	}
+	return gam(bp.Peak) - gam(bp.Under)/bp.Ratio
+}
+
+// BOLDEvent is the signal of each layer on one trial, at time T in drift steps
+type BOLDEvent struct {
+	T    float64
+	Vals []float64
+}
+
+// BOLDRecord records the signal of each layer on the current trial, at time
+// t in drift steps from the start of the first session, delayed by the
+// test trials recorded so far
+func (ss *Sim) BOLDRecord(t float64) {
+	if !ss.BOLD.On || ss.PreTraining {
+		return
+	}
+	ev := BOLDEvent{T: t + float64(ss.BOLDTstN), Vals: make([]float64, len(ss.LayStatNms))}
+	for li, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		if ss.BOLD.Signal == "Ge" {
+			ev.Vals[li] = float64(ly.Pools[0].Inhib.Ge.Avg) * float64(len(ly.Neurons))
+		} else {
+			ev.Vals[li] = float64(ly.Pools[0].ActM.Avg)
+		}
+	}
+	ss.BOLDEvents = append(ss.BOLDEvents, ev)
+}
+
+// BOLDTrnTrl records the current study trial -- called from LogTrnTrl
+func (ss *Sim) BOLDTrnTrl() {
+	ss.BOLDRecord(float64(ss.StudyTime(ss.TrainEnv.Epoch.Cur, ss.TrainEnv.Trial.Cur)))
+}
+
+// BOLDFiller records filler trial k of n, spread evenly over the gaplen
+// drift steps of the gap before study session epc -- called from FillerPhase
+func (ss *Sim) BOLDFiller(epc, k, n, gaplen int) {
+	t := float64(ss.StudyTime(epc-1, ss.Pat.ListSize-1)+1) + (float64(k)+.5)*float64(gaplen)/float64(n)
+	ss.BOLDRecord(t)
+}
+
+// BOLDTstTrl records the current test trial, at the end of the gap after
+// the last study session (before the first session for a test before any
+// study) -- the trials of all the tests follow one another, counted in
+// BOLDTstN.  Called from LogTstTrl.
+func (ss *Sim) BOLDTstTrl() {
+	if !ss.BOLD.On || ss.PreTraining {
+		return
+	}
+	epc := ss.TrainEnv.Epoch.Cur
+	if epc > ss.MaxEpcs {
+		epc = ss.MaxEpcs
+	}
+	t := 0
+	if epc > 0 {
+		t = ss.TestTime(epc) + 1
+	}
+	ss.BOLDRecord(float64(t))
+	ss.BOLDTstN++
+}
+
+// BOLDRun convolves the recorded signals with the HRF, and logs the BOLD
+// time series of each layer, one row per TR, to BOLDLog -- called at the
+// end of each run
+func (ss *Sim) BOLDRun() {
+	bp := &ss.BOLD
+	if len(ss.BOLDEvents) == 0 || bp.TR <= 0 || bp.StepSecs <= 0 {
+		return
+	}
+	end := 0.0
+	for _, ev := range ss.BOLDEvents {
+		end = math.Max(end, ev.T)
+	}
+	secs := (end+1)*bp.StepSecs + bp.Length
+	nvol := int(secs / bp.TR)
+	bold := make([]float64, len(ss.LayStatNms))
+	for v := 0; v < nvol; v++ {
+		tm := float64(v) * bp.TR
+		for li := range bold {
+			bold[li] = 0
+		}
+		for _, ev := range ss.BOLDEvents {
+			h := bp.HRF(tm - ev.T*bp.StepSecs)
+			if h == 0 {
+				continue
+			}
+			for li, val := range ev.Vals {
+				bold[li] += h * val
+			}
+		}
+		ss.LogBOLD(ss.BOLDLog, v, tm, bold)
+	}
+	ss.BOLDEvents = nil
+	ss.BOLDTstN = 0
+}
+
+//////////////////////////////////////////////
+//  BOLDLog
+
+// LogBOLD adds one volume of the BOLD time series of each layer to the BOLDLog
+func (ss *Sim) LogBOLD(dt *etable.Table, vol int, tm float64, bold []float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Vol", row, float64(vol))
+	dt.SetCellFloat("Time", row, tm)
+	for li, lnm := range ss.LayStatNms {
+		dt.SetCellFloat(lnm, row, bold[li])
+	}
+
+	if ss.BOLDPlot != nil && vol%100 == 0 {
+		ss.BOLDPlot.GoUpdate()
+	}
+	if ss.BOLDFile != nil {
+		if !ss.BOLDHdrs {
+			dt.WriteCSVHeaders(ss.BOLDFile, etable.Tab)
+			ss.BOLDHdrs = true
+		}
+		dt.WriteCSVRow(ss.BOLDFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigBOLDLog(dt *etable.Table) {
+	dt.SetMetaData("name", "BOLDLog")
+	dt.SetMetaData("desc", "Synthetic BOLD time series of each layer: per-trial signal over the run timeline convolved with the HRF, one row per TR (Time in seconds)")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Vol", etensor.INT64, nil, nil},
+		{"Time", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		sch = append(sch, etable.Column{lnm, etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigBOLDPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Synthetic BOLD Plot"
+	plt.Params.XAxisCol = "Time"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Vol", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Time", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	for _, lnm := range ss.LayStatNms {
+		plt.SetColParams(lnm, eplot.On, eplot.FloatMin, 0, eplot.FloatMax, 0)
+	}
+	return plt
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/leabra/hip"
+	"github.com/emer/leabra/leabra"
+)
+
+// CascadeParams are the parameters for multi-timescale (cascade) synapses
+// (Benna & Fusi, 2016): the visible linear weight (LWt) is coupled to a chain
+// of hidden variables with increasing capacitance and decreasing coupling, so
+// weight changes are slowly transferred into deeper, more stable variables,
+// which in turn pull the visible weight back after it is perturbed by
+// interference or decay.
+type CascadeParams struct {
+	On      bool    `desc:"use cascade synapses for the projection classes listed in Classes -- takes effect when the network is configured"`
+	Classes string  `desc:"comma-separated projection classes that get cascade synapses (PPath, HippoCHL) -- the no-learning mossy fibers are never included"`
+	NLevels int     `desc:"number of coupled variables per synapse, including the visible weight -- 1 = standard synapse"`
+	G       float32 `min:"0" max:"0.5" desc:"coupling conductance between the visible weight and the first hidden variable, per drift step"`
+	Ratio   float32 `min:"1" desc:"factor by which capacitance increases, and coupling decreases, with each deeper level"`
+	Leak    bool    `desc:"deepest variable leaks toward the initial weight mean -- otherwise the chain is closed and nothing is ever fully forgotten"`
+	TrialDt float32 `desc:"elapsed drift time per learning trial, integrated after each weight change"`
+}
+
+func (cp *CascadeParams) Defaults() {
+	cp.Classes = "PPath"
+	cp.NLevels = 4
+	cp.G = 0.25
+	cp.Ratio = 2
+	cp.Leak = true
+	cp.TrialDt = 1
+}
+
+func (cp *CascadeParams) Update() {
+	if cp.NLevels < 1 {
+		cp.NLevels = 1
+	}
+	if cp.G > 0.5 { // keeps the Euler integration stable
+		cp.G = 0.5
+	}
+	if cp.Ratio < 1 {
+		cp.Ratio = 1
+	}
+}
+
+// HasClass returns true if projection class cls gets cascade synapses
+func (cp *CascadeParams) HasClass(cls string) bool {
+	if !cp.On {
+		return false
+	}
+	for _, c := range strings.Split(cp.Classes, ",") {
+		if strings.TrimSpace(c) == cls {
+			return true
+		}
+	}
+	return false
+}
+
+// Elapser is implemented by projections with their own dynamics over elapsed
+// drift time, which are advanced in the gaps between study sessions
+type Elapser interface {
+	// Elapse advances the synaptic dynamics by t drift steps
+	Elapse(t float32)
+
+	// InitElapse resets any hidden state to match the current weights
+	InitElapse()
+
+	// InitElapseSyn resets the hidden state of synapse si (index into Syns)
+	// to match its current weight
+	InitElapseSyn(si int)
+}
+
+// CascadeSyns holds the hidden synaptic variables for one projection:
+// Hid[si*(NLevels-1)+k] is hidden level k+1 of synapse si.
+// Params point to the Sim's CascadeParams, so param sheets apply to all
+// cascade projections at once.
+type CascadeSyns struct {
+	Params *CascadeParams `view:"-" desc:"cascade parameters, shared across projections"`
+	Hid    []float32      `view:"-" desc:"hidden synaptic variables, NLevels-1 per synapse"`
+}
+
+// Init sets all hidden levels to the current visible LWt
+func (cs *CascadeSyns) Init(pj *leabra.Prjn) {
+	nh := cs.Params.NLevels - 1
+	if len(cs.Hid) != len(pj.Syns)*nh {
+		cs.Hid = make([]float32, len(pj.Syns)*nh)
+	}
+	for si := range pj.Syns {
+		lw := pj.Syns[si].LWt
+		for k := 0; k < nh; k++ {
+			cs.Hid[si*nh+k] = lw
+		}
+	}
+}
+
+// InitSyn sets the hidden levels of synapse si to its current visible LWt
+func (cs *CascadeSyns) InitSyn(pj *leabra.Prjn, si int) {
+	nh := cs.Params.NLevels - 1
+	if len(cs.Hid) != len(pj.Syns)*nh {
+		cs.Init(pj)
+		return
+	}
+	lw := pj.Syns[si].LWt
+	for k := 0; k < nh; k++ {
+		cs.Hid[si*nh+k] = lw
+	}
+}
+
+// Elapse integrates the cascade dynamics for t drift steps, updating the
+// visible LWt and Wt of every synapse.  Uses Euler steps of up to 0.5 / G.
+func (cs *CascadeSyns) Elapse(pj *leabra.Prjn, t float32) {
+	cp := cs.Params
+	nl := cp.NLevels
+	nh := nl - 1
+	if nh <= 0 || t <= 0 || cp.G <= 0 {
+		return
+	}
+	if len(cs.Hid) != len(pj.Syns)*nh {
+		cs.Init(pj)
+	}
+	nstep := int(math.Ceil(float64(t * 2 * cp.G)))
+	dt := t / float32(nstep)
+	cpc := make([]float32, nl) // capacitance of level k
+	gs := make([]float32, nl)  // coupling between level k and k+1 (last = leak)
+	for k := 0; k < nl; k++ {
+		rk := float32(math.Pow(float64(cp.Ratio), float64(k)))
+		cpc[k] = rk
+		gs[k] = cp.G / rk
+	}
+	if !cp.Leak {
+		gs[nh] = 0
+	}
+	mean := float32(pj.WtInit.Mean)
+	u := make([]float32, nl+1)
+	du := make([]float32, nl)
+	for si := range pj.Syns {
+		sy := &pj.Syns[si]
+		hid := cs.Hid[si*nh : (si+1)*nh]
+		u[0] = sy.LWt
+		copy(u[1:nl], hid)
+		u[nl] = mean // leak target for the deepest level
+		for st := 0; st < nstep; st++ {
+			for k := 0; k < nl; k++ {
+				in := gs[k] * (u[k+1] - u[k])
+				if k > 0 {
+					in += gs[k-1] * (u[k-1] - u[k])
+				}
+				du[k] = dt * in / cpc[k]
+			}
+			for k := 0; k < nl; k++ {
+				u[k] += du[k]
+			}
+		}
+		switch {
+		case u[0] > 1:
+			u[0] = 1
+		case u[0] < 0:
+			u[0] = 0
+		}
+		sy.LWt = u[0]
+		pj.Learn.WtFmLWt(sy)
+		copy(hid, u[1:nl])
+	}
+}
+
+// CascadePPathPrjn is a hip.EcCa1Prjn (used for the PPath projections) with cascade synapses
+type CascadePPathPrjn struct {
+	hip.EcCa1Prjn
+	Cascade CascadeSyns `desc:"cascade synapse state"`
+}
+
+func (pj *CascadePPathPrjn) InitWts() {
+	pj.EcCa1Prjn.InitWts()
+	pj.Cascade.Init(&pj.Prjn)
+}
+
+func (pj *CascadePPathPrjn) WtFmDWt() {
+	pj.EcCa1Prjn.WtFmDWt()
+	pj.Cascade.Elapse(&pj.Prjn, pj.Cascade.Params.TrialDt)
+}
+
+func (pj *CascadePPathPrjn) Elapse(t float32) {
+	pj.Cascade.Elapse(&pj.Prjn, t)
+}
+
+func (pj *CascadePPathPrjn) InitElapse() {
+	pj.Cascade.Init(&pj.Prjn)
+}
+
+func (pj *CascadePPathPrjn) InitElapseSyn(si int) {
+	pj.Cascade.InitSyn(&pj.Prjn, si)
+}
+
+// CascadeCHLPrjn is a hip.CHLPrjn (used for the HippoCHL projections) with cascade synapses
+type CascadeCHLPrjn struct {
+	hip.CHLPrjn
+	Cascade CascadeSyns `desc:"cascade synapse state"`
+}
+
+func (pj *CascadeCHLPrjn) InitWts() {
+	pj.CHLPrjn.InitWts()
+	pj.Cascade.Init(&pj.Prjn)
+}
+
+func (pj *CascadeCHLPrjn) WtFmDWt() {
+	pj.CHLPrjn.WtFmDWt()
+	pj.Cascade.Elapse(&pj.Prjn, pj.Cascade.Params.TrialDt)
+}
+
+func (pj *CascadeCHLPrjn) Elapse(t float32) {
+	pj.Cascade.Elapse(&pj.Prjn, t)
+}
+
+func (pj *CascadeCHLPrjn) InitElapse() {
+	pj.Cascade.Init(&pj.Prjn)
+}
+
+func (pj *CascadeCHLPrjn) InitElapseSyn(si int) {
+	pj.Cascade.InitSyn(&pj.Prjn, si)
+}
+
+// PPathPrjn returns a new projection for a PPath-class pathway, with cascade
+// synapses if selected in Cascade
+func (ss *Sim) PPathPrjn() emer.Prjn {
+	if ss.Cascade.HasClass("PPath") {
+		return &CascadePPathPrjn{Cascade: CascadeSyns{Params: &ss.Cascade}}
+	}
+	return &hip.EcCa1Prjn{}
+}
+
+// HippoCHLPrjn returns a new projection for a HippoCHL-class pathway, with
+// cascade synapses if selected in Cascade
+func (ss *Sim) HippoCHLPrjn() emer.Prjn {
+	if ss.Cascade.HasClass("HippoCHL") {
+		return &CascadeCHLPrjn{Cascade: CascadeSyns{Params: &ss.Cascade}}
+	}
+	return &hip.CHLPrjn{}
+}
+
+// ElapsePrjns advances all projections with their own elapsed-time dynamics
+// (e.g., cascade synapses) by t drift steps
+func (ss *Sim) ElapsePrjns(t float32) {
+	for _, ly := range ss.Net.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			if ep, ok := p.(Elapser); ok {
+				ep.Elapse(t)
+			}
+		}
+	}
+}
+
+// InitElapsePrjns resets the hidden state of all projections with elapsed-time
+// dynamics to their current weights -- needed after loading weights
+func (ss *Sim) InitElapsePrjns() {
+	for _, ly := range ss.Net.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			if ep, ok := p.(Elapser); ok {
+				ep.InitElapse()
+			}
+		}
+	}
+}
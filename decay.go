@@ -0,0 +1,143 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/emer/leabra/leabra"
+)
+
+// DecayParams control passive synaptic decay over elapsed drift time, applied
+// in the gaps between study sessions and in the retention interval before test.
+// Rule is one of:
+//
+//	Passive:  weights move linearly toward the initial mean, by Rate per drift step
+//	RandWalk: Gaussian noise with SD = Rate * elapsed steps (the original synap_decay)
+//	Exp:      exponential decay toward the initial mean with time constant Tau
+//	Power:    power-law decay toward the initial mean: (1 + t/Tau)^-Pow
+type DecayParams struct {
+	On        bool    `desc:"apply synaptic decay over elapsed drift time"`
+	Prjns     string  `desc:"comma-separated projection names to decay (e.g., ECinToCA3,CA3ToCA3) -- empty = all projections that learn"`
+	Rule      string  `desc:"decay rule: Passive, RandWalk, Exp, or Power"`
+	Rate      float64 `desc:"for Passive: proportion of the distance to the initial mean lost per drift step; for RandWalk: noise SD per drift step"`
+	Tau       float64 `desc:"time constant in drift steps for Exp and Power rules"`
+	Pow       float64 `desc:"exponent for the Power rule"`
+	WithinLst bool    `desc:"include the within-list drift (ListSize steps) in the elapsed time, as the original synap_decay did"`
+	Retention bool    `desc:"also decay over the retention interval before the final test"`
+}
+
+func (dp *DecayParams) Defaults() {
+	dp.Prjns = "ECinToCA3"
+	dp.Rule = "RandWalk"
+	dp.Rate = 0.0005
+	dp.Tau = 256
+	dp.Pow = 0.5
+	dp.WithinLst = true
+	dp.Retention = true
+}
+
+func (dp *DecayParams) Update() {
+	switch dp.Rule {
+	case "Passive", "RandWalk", "Exp", "Power":
+	default:
+		log.Printf("DecayParams: unknown Rule: %s -- using RandWalk\n", dp.Rule)
+		dp.Rule = "RandWalk"
+	}
+}
+
+// PrjnNames returns the list of projection names to decay
+func (dp *DecayParams) PrjnNames() []string {
+	var nms []string
+	for _, nm := range strings.Split(dp.Prjns, ",") {
+		nm = strings.TrimSpace(nm)
+		if nm != "" {
+			nms = append(nms, nm)
+		}
+	}
+	return nms
+}
+
+// DecayWt returns the decayed value of weight wt, with initial mean mean,
+// after t drift steps
+func (dp *DecayParams) DecayWt(wt, mean float32, t float64, rnd *rand.Rand) float32 {
+	switch dp.Rule {
+	case "Passive":
+		f := math.Min(1, dp.Rate*t)
+		wt += (mean - wt) * float32(f)
+	case "RandWalk":
+		wt += float32(rnd.NormFloat64() * dp.Rate * t)
+	case "Exp":
+		wt = mean + (wt-mean)*float32(math.Exp(-t/dp.Tau))
+	case "Power":
+		wt = mean + (wt-mean)*float32(math.Pow(1+t/dp.Tau, -dp.Pow))
+	}
+	switch {
+	case wt > 1:
+		wt = 1
+	case wt < 0:
+		wt = 0
+	}
+	return wt
+}
+
+// DecayPhase applies synaptic decay for the gap before study session epc
+// (epc == MaxEpcs is the retention interval before the final test), and
+// advances any projections with their own elapsed-time dynamics (cascade
+// synapses) and DG neurogenesis over the same gap.
+// Called at the epoch change in TrainTrial.
+func (ss *Sim) DecayPhase(epc int) {
+	dp := &ss.Decay
+	if epc <= 0 {
+		return
+	}
+	t := ss.GapLen(epc)
+	if t == 0 {
+		return
+	}
+	ss.ElapsePrjns(float32(t))
+	ss.Neurogenesis(float32(t))
+	if !dp.On || (epc >= ss.MaxEpcs && !dp.Retention) {
+		return
+	}
+	if dp.WithinLst {
+		t += ss.Pat.ListSize
+	}
+	ss.DecayPrjns(float64(t), rand.New(rand.NewSource(int64(epc-1))))
+}
+
+// DecayPrjns decays the weights of all projections in Decay.Prjns by
+// t drift steps, using rnd for any noise
+func (ss *Sim) DecayPrjns(t float64, rnd *rand.Rand) {
+	dp := &ss.Decay
+	var pjs []*leabra.Prjn
+	if nms := dp.PrjnNames(); len(nms) > 0 {
+		for _, nm := range nms {
+			pj := ss.PrjnByName(nm)
+			if pj == nil {
+				log.Printf("DecayPrjns: projection not found: %s\n", nm)
+				continue
+			}
+			pjs = append(pjs, pj)
+		}
+	} else {
+		for _, pj := range ss.Prjns() {
+			if pj.Learn.Learn {
+				pjs = append(pjs, pj)
+			}
+		}
+	}
+	for _, pj := range pjs {
+		mean := float32(pj.WtInit.Mean)
+		for si := range pj.Syns {
+			sy := &pj.Syns[si]
+			sy.Wt = dp.DecayWt(sy.Wt, mean, t, rnd)
+			pj.Learn.LWtFmWt(sy)
+		}
+	}
+}
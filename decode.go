@@ -0,0 +1,466 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// DecodeParams control the linear decoding analysis: cross-validated ridge
+// regression decoders (one-hot targets, arg-max readout) trained on the ActM
+// of each of the LayStatNms layers, from the trials of the Source log kept in
+// TrlStore over all sessions of the run.  The Session decoder predicts the
+// study session (or test epoch), holding out one item at a time, so it must
+// generalize over items.
+//
+// As each item keeps its list position (context row) in every session, item
+// identity cannot be told apart from position in the stored trials.  The Item
+// decoder instead runs probe trials without learning: the Source patterns of
+// the last session (study: A-B in its context; test: the AB test) with the
+// context rows rotated by Shifts evenly spaced shifts, so each item is seen
+// at Shifts different positions.  It holds out one shift at a time, so it
+// must generalize to items at positions not seen with them in training.
+type DecodeParams struct {
+	On     bool    `desc:"run the decoders after each test, keeping all trials in TrlStore (command line)"`
+	Source string  `desc:"trials to decode: Trn (study trials) or Tst (AB test trials)"`
+	Lambda float64 `min:"0" desc:"ridge penalty, relative to the mean squared norm of the training patterns"`
+	Shifts int     `min:"2" desc:"number of context shifts (list positions per item) of the Item decoder probes"`
+}
+
+func (dp *DecodeParams) Defaults() {
+	dp.Source = "Trn"
+	dp.Lambda = 0.1
+	dp.Shifts = 4
+}
+
+func (dp *DecodeParams) Update() {
+	switch dp.Source {
+	case "Trn", "Tst":
+	default:
+		log.Printf("DecodeParams: unknown Source: %s -- using Trn\n", dp.Source)
+		dp.Source = "Trn"
+	}
+	if dp.Shifts < 2 {
+		dp.Shifts = 2
+	}
+}
+
+// TrlStoreOn returns true if any analysis needs the TrlStore
+func (ss *Sim) TrlStoreOn() bool {
+	return ss.Decode.On || ss.RepDrift.On
+}
+
+// TrialItem returns the item (pattern row) of a trial name such as
+// TrainAB2_3, or -1 if it has none
+func TrialItem(nm string) int {
+	i, err := strconv.Atoi(nm[strings.LastIndex(nm, "_")+1:])
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
+// StoreTrl adds the ActM of each of the LayStatNms layers on the current
+// trial to TrlStore, which keeps the trials of all sessions of the run --
+// called from LogTrnTrl (src = Trn) and LogTstTrl (src = Tst), and reset in NewRun
+func (ss *Sim) StoreTrl(src, testNm string, epc int, trlNm string) {
+	if !ss.TrlStoreOn() {
+		return
+	}
+	dt := ss.TrlStore
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellString("Source", row, src)
+	dt.SetCellString("TestNm", row, testNm)
+	dt.SetCellString("TrialName", row, trlNm)
+	dt.SetCellFloat("Item", row, float64(TrialItem(trlNm)))
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		tsr := ss.ValsTsr(lnm)
+		ly.UnitValsTensor(tsr, "ActM")
+		dt.SetCellTensor(lnm+"ActM", row, tsr)
+	}
+}
+
+func (ss *Sim) ConfigTrlStore(dt *etable.Table) {
+	dt.SetMetaData("name", "TrlStore")
+	dt.SetMetaData("desc", "ActM of each trial over all sessions of the run, for the decoding and drift analyses")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Source", etensor.STRING, nil, nil},
+		{"TestNm", etensor.STRING, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"Item", etensor.INT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		sch = append(sch, etable.Column{lnm + "ActM", etensor.FLOAT64, ly.Shp.Shp, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+// StoreRows returns the TrlStore rows of given source, for the AB test if
+// src is Tst, with a valid item, up through epoch epc
+func (ss *Sim) StoreRows(src string, epc int) []int {
+	dt := ss.TrlStore
+	var rows []int
+	for r := 0; r < dt.Rows; r++ {
+		if dt.CellString("Source", r) != src || dt.CellFloat("Item", r) < 0 || int(dt.CellFloat("Epoch", r)) > epc {
+			continue
+		}
+		if src == "Tst" && dt.CellString("TestNm", r) != "AB" {
+			continue
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// RidgeDual fits a ridge regression of y (one row per pattern) on the
+// patterns x in its dual form, as n patterns are far fewer than units, and
+// returns the predictions for the patterns xt.  The patterns are centered on
+// the training mean, and the penalty is lambda times the mean squared norm
+// of the centered training patterns.
+func RidgeDual(x, y, xt [][]float64, lambda float64) [][]float64 {
+	n := len(x)
+	p := len(x[0])
+	k := len(y[0])
+	mu := make([]float64, p)
+	ym := make([]float64, k)
+	for i := range x {
+		for j, v := range x[i] {
+			mu[j] += v / float64(n)
+		}
+		for j, v := range y[i] {
+			ym[j] += v / float64(n)
+		}
+	}
+	xc := make([][]float64, n)
+	for i := range x {
+		xc[i] = make([]float64, p)
+		for j, v := range x[i] {
+			xc[i][j] = v - mu[j]
+		}
+	}
+	gram := make([][]float64, n)
+	tr := 0.0
+	for i := range xc {
+		gram[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			d := 0.0
+			for u := range xc[i] {
+				d += xc[i][u] * xc[j][u]
+			}
+			gram[i][j] = d
+			gram[j][i] = d
+		}
+		tr += gram[i][i]
+	}
+	pen := lambda * tr / float64(n)
+	if pen <= 0 {
+		pen = 1e-6
+	}
+	for i := range gram {
+		gram[i][i] += pen
+	}
+	yc := make([][]float64, n)
+	for i := range y {
+		yc[i] = make([]float64, k)
+		for j, v := range y[i] {
+			yc[i][j] = v - ym[j]
+		}
+	}
+	alpha := CholSolve(gram, yc)
+
+	pred := make([][]float64, len(xt))
+	for t := range xt {
+		kt := make([]float64, n)
+		for i := range xc {
+			d := 0.0
+			for u, v := range xt[t] {
+				d += (v - mu[u]) * xc[i][u]
+			}
+			kt[i] = d
+		}
+		pred[t] = make([]float64, k)
+		for j := range pred[t] {
+			s := ym[j]
+			for i := range kt {
+				s += kt[i] * alpha[i][j]
+			}
+			pred[t][j] = s
+		}
+	}
+	return pred
+}
+
+// CholSolve solves a * x = b for symmetric positive definite a, by Cholesky
+// decomposition, for each column of b
+func CholSolve(a, b [][]float64) [][]float64 {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				l[i][i] = math.Sqrt(math.Max(s, 1e-12))
+			} else {
+				l[i][j] = s / l[j][j]
+			}
+		}
+	}
+	x := make([][]float64, n)
+	for i := range x {
+		x[i] = make([]float64, len(b[i]))
+	}
+	for c := range b[0] {
+		z := make([]float64, n)
+		for i := 0; i < n; i++ {
+			s := b[i][c]
+			for k := 0; k < i; k++ {
+				s -= l[i][k] * z[k]
+			}
+			z[i] = s / l[i][i]
+		}
+		for i := n - 1; i >= 0; i-- {
+			s := z[i]
+			for k := i + 1; k < n; k++ {
+				s -= l[k][i] * x[k][c]
+			}
+			x[i][c] = s / l[i][i]
+		}
+	}
+	return x
+}
+
+// DecodeCV returns the cross-validated accuracy of decoding labels from the
+// patterns x, holding out each fold (group) in turn, and the number of
+// classes -- returns 0 accuracy if there are less than two classes or folds
+func DecodeCV(x [][]float64, labels, folds []int, lambda float64) (float64, int) {
+	cls := map[int]int{}
+	grps := map[int]bool{}
+	for i, lb := range labels {
+		if _, has := cls[lb]; !has {
+			cls[lb] = len(cls)
+		}
+		grps[folds[i]] = true
+	}
+	if len(cls) < 2 || len(grps) < 2 {
+		return 0, len(cls)
+	}
+	ncor, ntst := 0.0, 0.0
+	for g := range grps {
+		var trn, tst [][]float64
+		var y [][]float64
+		var tlb []int
+		for i := range x {
+			if folds[i] == g {
+				tst = append(tst, x[i])
+				tlb = append(tlb, cls[labels[i]])
+				continue
+			}
+			oh := make([]float64, len(cls))
+			oh[cls[labels[i]]] = 1
+			trn = append(trn, x[i])
+			y = append(y, oh)
+		}
+		if len(trn) == 0 {
+			continue
+		}
+		pred := RidgeDual(trn, y, tst, lambda)
+		for t, pr := range pred {
+			mx := 0
+			for j := range pr {
+				if pr[j] > pr[mx] {
+					mx = j
+				}
+			}
+			if mx == tlb[t] {
+				ncor++
+			}
+			ntst++
+		}
+	}
+	if ntst == 0 {
+		return 0, len(cls)
+	}
+	return ncor / ntst, len(cls)
+}
+
+// ShiftCtxt returns a copy of the Input patterns of dt in which each row i
+// has the context pools of row (i + shift) % rows -- the same items at
+// shifted list positions
+func (ss *Sim) ShiftCtxt(dt *etable.Table, shift int) *etensor.Float32 {
+	inp := dt.ColByName("Input").(*etensor.Float32)
+	sp := inp.Clone().(*etensor.Float32)
+	n := inp.Dim(0)
+	if n == 0 {
+		return sp
+	}
+	rsz := inp.Len() / n
+	st := 2 * ss.wpvc * ss.Hip.ECPool.Y * ss.Hip.ECPool.X // context pools, as in MemStats
+	for i := 0; i < n; i++ {
+		j := (i + shift) % n
+		copy(sp.Values[i*rsz+st:(i+1)*rsz], inp.Values[j*rsz+st:(j+1)*rsz])
+	}
+	return sp
+}
+
+// DecodeItemProbes runs the Item decoder probes for the session sess: each
+// item at each of the Decode.Shifts context shifts, without learning, and
+// returns the ActM of each of the LayStatNms layers, by layer, with the item
+// and shift of each probe
+func (ss *Sim) DecodeItemProbes(sess int) (map[string][][]float64, []int, []int) {
+	dt := ss.TestAB
+	if ss.Decode.Source == "Trn" {
+		dt = ss.SessionPats("DecodeItem", "A", "B", sess)
+	}
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	n := dt.Rows
+	acts := map[string][][]float64{}
+	var items, shifts []int
+	for s := 0; s < ss.Decode.Shifts; s++ {
+		pats := ss.ShiftCtxt(dt, s*n/ss.Decode.Shifts)
+		for i := 0; i < n; i++ {
+			ss.Net.InitActs()
+			ss.Net.InitExt()
+			input.ApplyExt(pats.SubSpace([]int{i}))
+			ss.SettleCyc(false, false)
+			for _, lnm := range ss.LayStatNms {
+				ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+				ly.UnitVals(&ss.TmpVals, "ActM")
+				x := make([]float64, len(ss.TmpVals))
+				for k, v := range ss.TmpVals {
+					x[k] = float64(v)
+				}
+				acts[lnm] = append(acts[lnm], x)
+			}
+			items = append(items, i)
+			shifts = append(shifts, s)
+		}
+	}
+	return acts, items, shifts
+}
+
+// DecodeTest runs the Session decoder for each layer on the stored trials of
+// the run so far, and the Item decoder on its probes of the last session,
+// and logs their accuracy to DecodeLog
+func (ss *Sim) DecodeTest() {
+	epc := ss.TrainEnv.Epoch.Prv
+	rows := ss.StoreRows(ss.Decode.Source, epc)
+	if len(rows) == 0 {
+		return
+	}
+	dt := ss.TrlStore
+	sess := make([]int, len(rows))
+	items := make([]int, len(rows))
+	for i, r := range rows {
+		sess[i] = int(dt.CellFloat("Epoch", r))
+		items[i] = int(dt.CellFloat("Item", r))
+	}
+	last := epc
+	if last > ss.MaxEpcs-1 {
+		last = ss.MaxEpcs - 1
+	}
+	pacts, pitems, pshifts := ss.DecodeItemProbes(last)
+	for _, lnm := range ss.LayStatNms {
+		x := make([][]float64, len(rows))
+		for i, r := range rows {
+			x[i] = dt.CellTensor(lnm+"ActM", r).(*etensor.Float64).Values
+		}
+		acc, ncls := DecodeCV(x, sess, items, ss.Decode.Lambda)
+		ss.LogDecode(ss.DecodeLog, lnm, "Session", acc, ncls)
+		acc, ncls = DecodeCV(pacts[lnm], pitems, pshifts, ss.Decode.Lambda)
+		ss.LogDecode(ss.DecodeLog, lnm, "Item", acc, ncls)
+	}
+}
+
+//////////////////////////////////////////////
+//  DecodeLog
+
+// LogDecode adds the accuracy of one decoder to the DecodeLog
+func (ss *Sim) LogDecode(dt *etable.Table, lnm, trg string, acc float64, ncls int) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	chance := 0.0
+	if ncls > 0 {
+		chance = 1 / float64(ncls)
+	}
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("Source", row, ss.Decode.Source)
+	dt.SetCellString("Layer", row, lnm)
+	dt.SetCellString("Target", row, trg)
+	dt.SetCellFloat("NClass", row, float64(ncls))
+	dt.SetCellFloat("Acc", row, acc)
+	dt.SetCellFloat("Chance", row, chance)
+
+	if ss.DecodePlot != nil {
+		ss.DecodePlot.GoUpdate()
+	}
+	if ss.DecodeFile != nil {
+		if !ss.DecodeHdrs {
+			dt.WriteCSVHeaders(ss.DecodeFile, etable.Tab)
+			ss.DecodeHdrs = true
+		}
+		dt.WriteCSVRow(ss.DecodeFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigDecodeLog(dt *etable.Table) {
+	dt.SetMetaData("name", "DecodeLog")
+	dt.SetMetaData("desc", "Cross-validated linear decoding accuracy of study session, and of item identity over shifted list positions, from each layer")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Source", etensor.STRING, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Target", etensor.STRING, nil, nil},
+		{"NClass", etensor.INT64, nil, nil},
+		{"Acc", etensor.FLOAT64, nil, nil},
+		{"Chance", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigDecodePlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Decoding Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.Params.LegendCol = "Layer"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Source", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Layer", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Target", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NClass", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Acc", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Chance", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
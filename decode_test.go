@@ -0,0 +1,129 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTrialItem(t *testing.T) {
+	tests := []struct {
+		nm   string
+		want int
+	}{
+		{"TrainAB2_3", 3},
+		{"TestAB_0", 0},
+		{"TrainAB_12", 12},
+		{"7", 7},
+		{"TestAB", -1},
+		{"TestAB_", -1},
+		{"TestAB_x", -1},
+		{"", -1},
+	}
+	for _, tt := range tests {
+		if got := TrialItem(tt.nm); got != tt.want {
+			t.Errorf("TrialItem(%q) = %d, want %d", tt.nm, got, tt.want)
+		}
+	}
+}
+
+func TestCholSolve(t *testing.T) {
+	a := [][]float64{{4, 2, 0}, {2, 5, 1}, {0, 1, 3}}
+	want := [][]float64{{1, 2}, {-1, 0}, {2, 1}}
+	b := make([][]float64, len(a))
+	for i := range a {
+		b[i] = make([]float64, len(want[0]))
+		for c := range b[i] {
+			for k := range a {
+				b[i][c] += a[i][k] * want[k][c]
+			}
+		}
+	}
+	got := CholSolve(a, b)
+	for i := range want {
+		for c := range want[i] {
+			if math.Abs(got[i][c]-want[i][c]) > 1e-9 {
+				t.Errorf("CholSolve x[%d][%d] = %g, want %g", i, c, got[i][c], want[i][c])
+			}
+		}
+	}
+}
+
+func TestRidgeDual(t *testing.T) {
+	// y = 2 x0 - x1 + 3 is fit exactly with a negligible penalty
+	x := [][]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 1}}
+	y := make([][]float64, len(x))
+	for i, v := range x {
+		y[i] = []float64{2*v[0] - v[1] + 3}
+	}
+	xt := [][]float64{{3, -2}, {0.5, 0.5}}
+	want := []float64{11, 3.5}
+	pred := RidgeDual(x, y, xt, 0)
+	for i := range want {
+		if math.Abs(pred[i][0]-want[i]) > 1e-4 {
+			t.Errorf("RidgeDual prediction %d = %g, want %g", i, pred[i][0], want[i])
+		}
+	}
+}
+
+// decodePats returns nper noisy patterns of each of ncls random prototypes,
+// with their class labels and folds (the repetition of the class)
+func decodePats(rnd *rand.Rand, ncls, nper, nunits int, noise float64) ([][]float64, []int, []int) {
+	protos := make([][]float64, ncls)
+	for c := range protos {
+		protos[c] = make([]float64, nunits)
+		for u := range protos[c] {
+			if rnd.Float64() < 0.25 {
+				protos[c][u] = 1
+			}
+		}
+	}
+	var x [][]float64
+	var labels, folds []int
+	for r := 0; r < nper; r++ {
+		for c := range protos {
+			p := make([]float64, nunits)
+			for u, v := range protos[c] {
+				p[u] = v + noise*rnd.NormFloat64()
+			}
+			x = append(x, p)
+			labels = append(labels, c)
+			folds = append(folds, r)
+		}
+	}
+	return x, labels, folds
+}
+
+func TestDecodeCV(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x, labels, folds := decodePats(rnd, 4, 5, 40, 0.1)
+	acc, ncls := DecodeCV(x, labels, folds, 0.1)
+	if acc != 1 || ncls != 4 {
+		t.Errorf("DecodeCV of separable classes = %g, %d classes, want 1, 4", acc, ncls)
+	}
+
+	// labels shuffled over the patterns: about chance
+	x, labels, folds = decodePats(rnd, 2, 100, 40, 0.1)
+	rnd.Shuffle(len(labels), func(i, j int) { labels[i], labels[j] = labels[j], labels[i] })
+	for i := range folds {
+		folds[i] = i % 10
+	}
+	acc, ncls = DecodeCV(x, labels, folds, 0.1)
+	if math.Abs(acc-0.5) > 0.15 || ncls != 2 {
+		t.Errorf("DecodeCV of shuffled labels = %g, %d classes, want about 0.5, 2", acc, ncls)
+	}
+}
+
+func TestDecodeCVDegenerate(t *testing.T) {
+	x := [][]float64{{1, 0}, {0, 1}, {1, 1}, {0, 0}}
+	if acc, ncls := DecodeCV(x, []int{3, 3, 3, 3}, []int{0, 1, 0, 1}, 0.1); acc != 0 || ncls != 1 {
+		t.Errorf("DecodeCV of one class = %g, %d classes, want 0, 1", acc, ncls)
+	}
+	if acc, ncls := DecodeCV(x, []int{0, 1, 0, 1}, []int{2, 2, 2, 2}, 0.1); acc != 0 || ncls != 2 {
+		t.Errorf("DecodeCV of one fold = %g, %d classes, want 0, 2", acc, ncls)
+	}
+}
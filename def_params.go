@@ -96,10 +96,8 @@ var ParamSets = params.Sets{
 					"Prjn.Learn.Norm.On":     "false",
 					"Prjn.Learn.WtBal.On":    "true",
 				}},
-			{Sel: "#CA3ToCA1", Desc: "Schaffer collaterals -- slower, less hebb",
+			{Sel: "#CA3ToCA1", Desc: "Schaffer collaterals -- slower, less hebb (CHL params in CA3CA1_CHL)",
 				Params: params.Params{
-					"Prjn.CHL.Hebb":          "0.01", // .01 > .005 > .02 > .002 > .001 > .05 (crazy)
-					"Prjn.CHL.SAvgCor":       "0.4",
 					"Prjn.Learn.Lrate":       "0.1", // CHL: .1 =~ .08 > .15 > .2, .05 (sig worse)
 					"Prjn.Learn.Momentum.On": "false",
 					"Prjn.Learn.Norm.On":     "false",
@@ -250,6 +248,33 @@ var ParamSets = params.Sets{
 				}},
 		},
 	}},
+	{Name: "CA3CA1_CHL", Desc: "params for the CHL CA3 -> CA1 projection, in all but the XCalCA3CA1 architecture variant", Sheets: params.Sheets{
+		"Network": &params.Sheet{
+			{Sel: "#CA3ToCA1", Desc: "Schaffer collaterals -- slower, less hebb",
+				Params: params.Params{
+					"Prjn.CHL.Hebb":    "0.01", // .01 > .005 > .02 > .002 > .001 > .05 (crazy)
+					"Prjn.CHL.SAvgCor": "0.4",
+				}},
+		},
+	}},
+	{Name: "Arch_XCalCA3CA1", Desc: "params for the XCalCA3CA1 architecture variant", Sheets: params.Sheets{
+		"Network": &params.Sheet{
+			{Sel: "#CA3ToCA1", Desc: "default XCal prjn needs a much higher lrate",
+				Params: params.Params{
+					"Prjn.Learn.Lrate": "1.0", // 1.0 or maybe 1.2
+				}},
+		},
+	}},
+	{Name: "Arch_BCM", Desc: "params for the BCM architecture variant", Sheets: params.Sheets{
+		"Network": &params.Sheet{
+			{Sel: ".PPath", Desc: "all BCM (no EDL) on the perforant path and CA3 recurrents",
+				Params: params.Params{
+					"Prjn.Learn.XCal.SetLLrn": "true",
+					"Prjn.Learn.XCal.MLrn":    "0",
+					"Prjn.Learn.XCal.LLrn":    "1",
+				}},
+		},
+	}},
 	{Name: "SmallHip", Desc: "hippo size", Sheets: params.Sheets{
 		"Hip": &params.Sheet{
 			{Sel: "HipParams", Desc: "hip sizes",
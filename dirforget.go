@@ -0,0 +1,226 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// DirForgetParams control the list-method directed forgetting paradigm: the
+// first L1Epcs study sessions are list 1 (the A-B pairs), and the rest are
+// list 2 (new L2A-L2B pairs).  Between the lists, a Forget cue shifts the
+// context, either by a Jump of the slowest Pools context pools to new random
+// patterns, or by Drift at DriftMult times the normal rate over the gap,
+// while a Remember cue leaves the normal drift.  The tests measure the
+// recall of both lists, and the similarity of the test context to the
+// context at the end of each list.
+type DirForgetParams struct {
+	On        bool    `desc:"use the directed forgetting paradigm (command line)"`
+	Cue       string  `desc:"cue between the lists: Forget or Remember"`
+	Shift     string  `desc:"context shift triggered by a Forget cue: Jump or Drift"`
+	L1Epcs    int     `min:"1" desc:"number of list 1 sessions -- the remaining MaxEpcs sessions are list 2"`
+	Pools     int     `desc:"for Jump, number of context pools, slowest first, that jump to new random patterns"`
+	DriftMult float32 `desc:"for Drift, multiplier on the drift rate of each context pool over the gap between the lists"`
+}
+
+func (dp *DirForgetParams) Defaults() {
+	dp.Shift = "Jump"
+	dp.L1Epcs = 1
+	dp.Pools = 4
+	dp.DriftMult = 4
+}
+
+func (dp *DirForgetParams) Update() {
+	switch dp.Cue {
+	case "Forget", "Remember":
+	default:
+		if dp.On {
+			log.Printf("DirForgetParams: unknown Cue: %s -- using Forget\n", dp.Cue)
+			dp.Cue = "Forget"
+		}
+	}
+	switch dp.Shift {
+	case "Jump", "Drift":
+	default:
+		log.Printf("DirForgetParams: unknown Shift: %s -- using Jump\n", dp.Shift)
+		dp.Shift = "Jump"
+	}
+}
+
+// DirForgetL1Epcs returns the number of list 1 sessions
+func (ss *Sim) DirForgetL1Epcs() int {
+	n1 := ss.DirForget.L1Epcs
+	if n1 >= ss.MaxEpcs {
+		log.Printf("DirForget: need at least one list 2 session -- MaxEpcs: %d, L1Epcs: %d\n", ss.MaxEpcs, n1)
+		n1 = ss.MaxEpcs - 1
+	}
+	if n1 < 1 {
+		n1 = 1
+	}
+	return n1
+}
+
+// DirForgetCtxts regenerates the list 2 session contexts for context pool i,
+// applying the context shift of a Forget cue over the gap after the last list
+// 1 session, with given between- and within-session drift rates.  Called
+// from ConfigPats after the session contexts are made, so the test contexts
+// follow on from the last list 2 session.
+func (ss *Sim) DirForgetCtxts(i int, drv, drvL float32) {
+	dp := &ss.DirForget
+	n1 := ss.DirForgetL1Epcs()
+	npats := ss.Pat.ListSize
+	if n1-1 >= len(ss.fillers) {
+		return
+	}
+	gap := ss.fillers[n1-1]
+	patgen.AddVocabClone(ss.PoolVocab, "clone", SessionCtxt(n1-1, i))
+	gdrv := drv
+	if dp.Cue == "Forget" {
+		switch dp.Shift {
+		case "Jump":
+			if i >= ss.cvcn*2-dp.Pools {
+				patgen.AddVocabPermutedBinary(ss.PoolVocab, "clone", npats, ss.Hip.ECPool.Y, ss.Hip.ECPool.X, ss.Hip.ECPctAct, ss.Pat.MinDiffPct)
+			}
+		case "Drift":
+			gdrv *= dp.DriftMult
+			if gdrv > 1 {
+				gdrv = 1
+			}
+		}
+	}
+	patgen.AddVocabDrift(ss.PoolVocab, GapCtxt(n1-1, i), gap+1, gdrv, "clone", npats-1)
+	patgen.AddVocabDrift(ss.PoolVocab, SessionCtxt(n1, i), npats, drvL, GapCtxt(n1-1, i), gap-1)
+	ss.ChainSessionCtxts(i, n1+1, drv, drvL)
+}
+
+// ConfigDirForgetPats makes the list 2 vocabs, and the TestL2 patterns --
+// called at the end of ConfigPats
+func (ss *Sim) ConfigDirForgetPats() {
+	hp := &ss.Hip
+	npats := ss.Pat.ListSize
+	for _, v := range []string{"L2A", "L2B"} {
+		for i := 1; i <= 4; i++ {
+			patgen.AddVocabPermutedBinary(ss.PoolVocab, v+strconv.Itoa(i), npats, hp.ECPool.Y, hp.ECPool.X, hp.ECPctAct, ss.Pat.MinDiffPct)
+		}
+	}
+	ss.TestPats(ss.TestL2, "TestL2", "L2A", "L2B")
+}
+
+// DirForgetSession sets the training patterns for the list 2 sessions --
+// called at the start of each session (epoch), after the A-B table is set
+func (ss *Sim) DirForgetSession(epc int) {
+	n1 := ss.DirForgetL1Epcs()
+	if epc < n1 || epc >= ss.MaxEpcs {
+		return
+	}
+	ss.TrainEnv.Table = etable.NewIdxView(ss.SessionPats(fmt.Sprintf("TrainL2_%d", epc-n1+1), "L2A", "L2B", epc))
+	ss.TrainEnv.SetTrialName()
+	ss.TrainEnv.SetGroupName()
+}
+
+// DirForgetCtxtSim returns the mean cosine, over context pools, between the
+// first test context and the context at the end of study session epc
+func (ss *Sim) DirForgetCtxtSim(epc int) float64 {
+	npats := ss.Pat.ListSize
+	sum := 0.0
+	for i := 0; i < ss.cvcn*2; i++ {
+		tv := ss.PoolVocab[fmt.Sprintf("ctxtT_%d", i+1)].SubSpace([]int{0}).(*etensor.Float32)
+		sv := ss.PoolVocab[SessionCtxt(epc, i)].SubSpace([]int{npats - 1}).(*etensor.Float32)
+		sum += float64(metric.Cosine32(tv.Values, sv.Values))
+	}
+	return sum / float64(ss.cvcn*2)
+}
+
+// DirForgetTest tests the recall of list 1 (TestAB) and list 2 (TestL2),
+// and logs it to DirForgetLog along with the test context similarity to
+// the end of each list
+func (ss *Sim) DirForgetTest() {
+	mem := func(dt *etable.Table) float64 {
+		sum, n := 0.0, 0.0
+		ss.TestTable(dt, func() {
+			sum += ss.Mem
+			n++
+		})
+		if n == 0 {
+			return 0
+		}
+		return sum / n
+	}
+	l1 := mem(ss.TestAB)
+	l2 := mem(ss.TestL2)
+	ss.LogDirForget(ss.DirForgetLog, l1, l2, ss.DirForgetCtxtSim(ss.DirForgetL1Epcs()-1), ss.DirForgetCtxtSim(ss.MaxEpcs-1))
+}
+
+//////////////////////////////////////////////
+//  DirForgetLog
+
+// LogDirForget adds the results of one directed forgetting test to the DirForgetLog
+func (ss *Sim) LogDirForget(dt *etable.Table, l1, l2, c1, c2 float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("Cue", row, ss.DirForget.Cue)
+	dt.SetCellString("Shift", row, ss.DirForget.Shift)
+	dt.SetCellFloat("L1 Mem", row, l1)
+	dt.SetCellFloat("L2 Mem", row, l2)
+	dt.SetCellFloat("L1 CtxtSim", row, c1)
+	dt.SetCellFloat("L2 CtxtSim", row, c2)
+
+	if ss.DirFgtPlot != nil {
+		ss.DirFgtPlot.GoUpdate()
+	}
+	if ss.DirFgtFile != nil {
+		if !ss.DirFgtHdrs {
+			dt.WriteCSVHeaders(ss.DirFgtFile, etable.Tab)
+			ss.DirFgtHdrs = true
+		}
+		dt.WriteCSVRow(ss.DirFgtFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigDirForgetLog(dt *etable.Table) {
+	dt.SetMetaData("name", "DirForgetLog")
+	dt.SetMetaData("desc", "List-method directed forgetting: recall of list 1 and list 2, and test context similarity to the end of each list")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Cue", etensor.STRING, nil, nil},
+		{"Shift", etensor.STRING, nil, nil},
+		{"L1 Mem", etensor.FLOAT64, nil, nil},
+		{"L2 Mem", etensor.FLOAT64, nil, nil},
+		{"L1 CtxtSim", etensor.FLOAT64, nil, nil},
+		{"L2 CtxtSim", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigDirForgetPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Directed Forgetting Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Cue", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Shift", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("L1 Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("L2 Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("L1 CtxtSim", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("L2 CtxtSim", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// EDQtrs are the quarter activity variables for the error differences, by
+// quarter name -- Q1 and Q2 at the end of those quarters, M at the end of
+// the minus phase (Q3), and P at the end of the plus phase (Q4)
+var EDQtrs = map[string]string{"Q1": "ActQ1", "Q2": "ActQ2", "M": "ActM", "P": "ActP"}
+
+// EDQtrNums are the quarter numbers used in the ED column names
+var EDQtrNums = map[string]string{"Q1": "1", "Q2": "2", "M": "3", "P": "4"}
+
+// EDParams control the error difference (ED) statistics: for each of the
+// LayStatNms layers and each pair of quarters, the absolute difference in
+// unit activity between the two quarters, normalized by the number of units
+// and the layer average activity -- the error signal available for
+// error-driven learning.  They are logged per trial and per epoch, for
+// training and testing, as <layer> ED<ab> with quarters numbered 1..4
+// (e.g., CA3 ED14 for Q1-P, ECout ED34 for M-P).
+type EDParams struct {
+	Pairs string `desc:"comma-separated pairs of quarters (Q1, Q2, M, P), e.g., Q1-P,M-P -- applies to logs configured afterward (command line)"`
+}
+
+func (ep *EDParams) Defaults() {
+	ep.Pairs = "Q1-P,M-P"
+}
+
+func (ep *EDParams) Update() {
+}
+
+// EDPair is a pair of quarters for an error difference
+type EDPair struct {
+	A, B string
+}
+
+// Name returns the column name of the pair, e.g., ED14
+func (pr EDPair) Name() string {
+	return "ED" + EDQtrNums[pr.A] + EDQtrNums[pr.B]
+}
+
+// PairList returns the valid quarter pairs
+func (ep *EDParams) PairList() []EDPair {
+	var prs []EDPair
+	for _, p := range CommaList(ep.Pairs) {
+		qs := strings.Split(p, "-")
+		if len(qs) != 2 {
+			log.Printf("EDParams: bad quarter pair: %s\n", p)
+			continue
+		}
+		pr := EDPair{strings.TrimSpace(qs[0]), strings.TrimSpace(qs[1])}
+		if _, ok := EDQtrs[pr.A]; !ok {
+			log.Printf("EDParams: unknown quarter: %s\n", pr.A)
+			continue
+		}
+		if _, ok := EDQtrs[pr.B]; !ok {
+			log.Printf("EDParams: unknown quarter: %s\n", pr.B)
+			continue
+		}
+		prs = append(prs, pr)
+	}
+	return prs
+}
+
+// EDCols returns the ED column names, for each layer and quarter pair
+func (ss *Sim) EDCols() []string {
+	var cols []string
+	for _, lnm := range ss.LayStatNms {
+		for _, pr := range ss.ED.PairList() {
+			cols = append(cols, lnm+" "+pr.Name())
+		}
+	}
+	return cols
+}
+
+// LayED returns the error difference of layer lnm between the quarters of pr
+func (ss *Sim) LayED(lnm string, pr EDPair) float64 {
+	ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+	tsra := ss.ValsTsr(lnm + "ED" + pr.A)
+	tsrb := ss.ValsTsr(lnm + "ED" + pr.B)
+	ly.UnitValsTensor(tsra, EDQtrs[pr.A])
+	ly.UnitValsTensor(tsrb, EDQtrs[pr.B])
+	actavgg := ly.Pools[0].Inhib.Act.Avg // average for this single trial, effective, most stable
+	if actavgg <= 0 {
+		return 0
+	}
+	return float64(metric.Abs32(tsra.Values, tsrb.Values) / (actavgg * float32(tsrb.Len())))
+}
+
+// EDStats computes the error differences of the current trial into TrlEDs,
+// by column name -- called from TrialStats
+func (ss *Sim) EDStats() {
+	if ss.TrlEDs == nil {
+		ss.TrlEDs = map[string]float64{}
+	}
+	if ss.EDSums == nil {
+		ss.EDSums = map[string]float64{}
+	}
+	prs := ss.ED.PairList()
+	for _, lnm := range ss.LayStatNms {
+		for _, pr := range prs {
+			ss.TrlEDs[lnm+" "+pr.Name()] = ss.LayED(lnm, pr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEDPairList(t *testing.T) {
+	tests := []struct {
+		pairs string
+		want  []EDPair
+	}{
+		{"", nil},
+		{"Q1-P,M-P", []EDPair{{"Q1", "P"}, {"M", "P"}}},
+		{" Q2 - M , ", []EDPair{{"Q2", "M"}}},
+		{"Q1,Q1-Q2-P,Q3-P,M-X,Q1-Q2", []EDPair{{"Q1", "Q2"}}},
+	}
+	for _, tt := range tests {
+		ep := EDParams{Pairs: tt.pairs}
+		if got := ep.PairList(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PairList(%q) = %v, want %v", tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestEDPairName(t *testing.T) {
+	tests := []struct {
+		pr   EDPair
+		want string
+	}{
+		{EDPair{"Q1", "P"}, "ED14"},
+		{EDPair{"M", "P"}, "ED34"},
+		{EDPair{"Q2", "M"}, "ED23"},
+	}
+	for _, tt := range tests {
+		if got := tt.pr.Name(); got != tt.want {
+			t.Errorf("%v.Name() = %q, want %q", tt.pr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// FillerParams control filler-task learning in the gaps between study
+// sessions: the network studies new, unrelated filler pairs, spread evenly
+// over the drift steps of the gap and in the drifting context of those
+// steps, so forgetting can come from interference as well as context change.
+// The number of filler trials scales with the gap length, in the same drift
+// step units as the fillers.
+type FillerParams struct {
+	On        bool    `desc:"run filler trials in the gaps between study sessions (command line)"`
+	Gaps      []int   `desc:"which gaps get filler trials (0 = gap after the first study session) -- empty = all gaps"`
+	Retention bool    `desc:"also run filler trials in the retention interval before the final test"`
+	PerFiller float32 `desc:"density: filler trials per drift step of the gap (fillers, testlag)"`
+	Learn     bool    `desc:"learn (DWt, WtFmDWt) from the filler trials"`
+}
+
+func (fp *FillerParams) Defaults() {
+	fp.PerFiller = 0.25
+	fp.Learn = true
+}
+
+func (fp *FillerParams) Update() {
+}
+
+// HasGap returns true if given gap index gets filler trials
+func (fp *FillerParams) HasGap(gap int) bool {
+	if len(fp.Gaps) == 0 {
+		return true
+	}
+	for _, g := range fp.Gaps {
+		if g == gap {
+			return true
+		}
+	}
+	return false
+}
+
+// NFillers returns the number of filler trials for a gap of given drift length
+func (fp *FillerParams) NFillers(gaplen int) int {
+	return int(fp.PerFiller*float32(gaplen) + .5)
+}
+
+// GapCtxt returns the name of the context vocab for context pool i (0-based)
+// over the drift steps of the gap after study session gap (0-based)
+func GapCtxt(gap, i int) string {
+	return fmt.Sprintf("fill%d_%d", gap+1, i+1)
+}
+
+// FillerPats makes n filler trial patterns: new filler pairs, in the context
+// of n drift steps spread evenly over the gap, taken from the gap context
+// vocabs named by ctxt
+func (ss *Sim) FillerPats(n, gaplen int, ctxt func(i int) string) *etable.Table {
+	hp := &ss.Hip
+	var inp []string
+	for _, v := range []string{"fillA", "fillB"} {
+		for p := 1; p <= 4; p++ {
+			nm := v + strconv.Itoa(p)
+			patgen.AddVocabPermutedBinary(ss.PoolVocab, nm, n, hp.ECPool.Y, hp.ECPool.X, hp.ECPctAct, ss.Pat.MinDiffPct)
+			inp = append(inp, nm)
+		}
+	}
+	for i := 0; i < ss.cvcn*2; i++ {
+		nm := fmt.Sprintf("fillctxt_%d", i+1)
+		fc, _ := patgen.AddVocabEmpty(ss.PoolVocab, nm, n, hp.ECPool.Y, hp.ECPool.X)
+		gc := ss.PoolVocab[ctxt(i)]
+		for k := 0; k < n; k++ {
+			r := int((float32(k) + .5) * float32(gaplen) / float32(n))
+			if r >= gc.Dim(0) {
+				r = gc.Dim(0) - 1
+			}
+			copy(fc.SubSpace([]int{k}).(*etensor.Float32).Values, gc.SubSpace([]int{r}).(*etensor.Float32).Values)
+		}
+		inp = append(inp, nm)
+	}
+	dt := &etable.Table{}
+	patgen.InitPats(dt, "Filler_", "Filler trial Pats", "Input", "ECout", n, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+	patgen.MixPats(dt, ss.PoolVocab, "ECout", inp)
+	return dt
+}
+
+// FillerPhase runs the filler trials for the gap before study session epc
+// (epc == MaxEpcs is the retention interval before the final test).
+// Called at the epoch change in TrainTrial, after the replay phase.
+func (ss *Sim) FillerPhase(epc int) {
+	fp := &ss.Filler
+	if !fp.On || epc <= 0 {
+		return
+	}
+	gap := epc - 1
+	ctxt := func(i int) string { return GapCtxt(gap, i) }
+	if epc >= ss.MaxEpcs {
+		if !fp.Retention {
+			return
+		}
+		ctxt = func(i int) string { return fmt.Sprintf("lagbeforetest_%d", i+1) }
+	} else if !fp.HasGap(gap) {
+		return
+	}
+	gaplen := ss.GapLen(epc)
+	n := fp.NFillers(gaplen)
+	if n <= 0 {
+		return
+	}
+
+	dt := ss.FillerPats(n, gaplen, ctxt)
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	unsums := ss.SaveTrlSums() // fillers are not part of the study epoch stats
+	for ri := 0; ri < dt.Rows; ri++ {
+		ss.Net.InitActs()
+		ss.Net.InitExt()
+		input.ApplyExt(dt.CellTensor("Input", ri))
+		ecout.ApplyExt(dt.CellTensor("ECout", ri))
+		if fp.Learn {
+			ss.AlphaCyc(true)
+		} else {
+			ss.SettleCyc(true, false)
+		}
+		ss.BOLDFiller(epc, ri, n, gaplen)
+	}
+	unsums()
+}
@@ -0,0 +1,12 @@
+module github.com/JamesWardAntony/HipSTeR
+
+go 1.15
+
+require (
+	github.com/emer/emergent v1.1.18
+	github.com/emer/etable v1.0.23
+	github.com/emer/leabra v1.1.30
+	github.com/goki/gi v1.2.2
+	github.com/goki/ki v1.1.1
+	github.com/goki/mat32 v1.0.3
+)
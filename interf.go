@@ -0,0 +1,230 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// InterfParams control the proactive / retroactive interference experiments
+// (exptype 2 = pi/, 3 = ri/, 4 = rin/, where the AC cues are new): the last
+// ACEpcs study sessions are A-C (TrainAC, TrainAC2, ...), starting Lag drift
+// steps after the last A-B session, and the test is RI drift steps after the
+// last A-C session.  Each test measures recall of both A-B and A-C, and the
+// intrusions of C when recalling B and vice versa.  Design selects the
+// exptype on the command line, as the expnum ranges only reach fcurve.
+type InterfParams struct {
+	Design  string  `desc:"PI, RI or RIn: if set, overrides the exptype from expnum (command line)"`
+	Lag     int     `min:"1" desc:"drift steps between the end of the last A-B session and the first A-C session"`
+	RI      int     `min:"1" desc:"retention interval: drift steps between the end of the last A-C session and the test"`
+	ACEpcs  int     `min:"1" desc:"number of A-C sessions, at the end of the MaxEpcs sessions"`
+	IntrThr float32 `desc:"minimum cosine of the ECout target pools with the competing associate for a failed recall to count as an intrusion -- the competitor must also match better than the correct target"`
+}
+
+func (ip *InterfParams) Defaults() {
+	ip.Lag = 16
+	ip.RI = 1024
+	ip.ACEpcs = 1
+	ip.IntrThr = 0.5
+}
+
+func (ip *InterfParams) Update() {
+	if ip.Lag < 1 {
+		ip.Lag = 1
+	}
+	if ip.RI < 1 {
+		ip.RI = 1
+	}
+	if ip.ACEpcs < 1 {
+		ip.ACEpcs = 1
+	}
+}
+
+// ExpType returns the exptype for the Design, or -1 if not set
+func (ip *InterfParams) ExpType() int {
+	switch ip.Design {
+	case "":
+		return -1
+	case "PI":
+		return 2
+	case "RI":
+		return 3
+	case "RIn":
+		return 4
+	}
+	log.Printf("InterfParams: unknown Design: %s -- using PI\n", ip.Design)
+	return 2
+}
+
+// InterfABEpcs returns the number of A-B sessions, before the A-C sessions
+func (ss *Sim) InterfABEpcs() int {
+	nab := ss.MaxEpcs - ss.Interf.ACEpcs
+	if nab < 1 {
+		log.Printf("Interf: need at least one A-B session -- MaxEpcs: %d, ACEpcs: %d\n", ss.MaxEpcs, ss.Interf.ACEpcs)
+		nab = 1
+	}
+	return nab
+}
+
+// InterfCtxts regenerates the A-C session contexts for context pool i, with
+// the first starting Lag drift steps after the end of the last A-B session,
+// and sets the ctxt_AC context vocab to that of the first A-C session.
+// Called from ConfigPats after the session contexts are made.  Past the
+// fillers, ctxt_AC is that of the last session that has a context.
+func (ss *Sim) InterfCtxts(i int, drv, drvL float32) {
+	nab := ss.InterfABEpcs()
+	if nab-1 >= len(ss.fillers) {
+		if i == 0 {
+			log.Printf("Interf: no gap after A-B session %d to set Lag -- max sessions: %d\n", nab, len(ss.fillers)+1)
+		}
+		patgen.AddVocabClone(ss.PoolVocab, fmt.Sprintf("ctxt_AC%d", i+1), SessionCtxt(len(ss.fillers), i))
+		return
+	}
+	ss.fillers[nab-1] = ss.Interf.Lag
+	ss.ChainSessionCtxts(i, nab, drv, drvL)
+	patgen.AddVocabClone(ss.PoolVocab, fmt.Sprintf("ctxt_AC%d", i+1), SessionCtxt(nab, i))
+}
+
+// ConfigInterfPats makes the TrainAC2 patterns for a second A-C session --
+// called at the end of ConfigPats, after TrainAC is made
+func (ss *Sim) ConfigInterfPats() {
+	nab := ss.InterfABEpcs()
+	if nab+1 < ss.MaxEpcs {
+		ss.TrainAC2 = ss.SessionPats("TrainAC2", "A", "C", nab+1)
+	}
+}
+
+// InterfSession sets the training patterns for the A-C sessions -- called
+// at the start of each session (epoch), after the A-B table is set
+func (ss *Sim) InterfSession(epc int) {
+	nab := ss.InterfABEpcs()
+	switch {
+	case epc == nab:
+		ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAC)
+	case epc == nab+1 && epc < ss.MaxEpcs:
+		ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAC2)
+	case epc > nab+1 && epc < ss.MaxEpcs:
+		ss.TrainEnv.Table = etable.NewIdxView(ss.SessionPats(fmt.Sprintf("TrainAC%d", epc-nab+1), "A", "C", epc))
+	default:
+		return
+	}
+	ss.TrainEnv.SetTrialName()
+	ss.TrainEnv.SetGroupName()
+}
+
+// InterfTestTable tests the items in dt, returning the mean Mem, and the
+// proportion of intrusions of the competing associates, which are the
+// targets of the same rows of cdt
+func (ss *Sim) InterfTestTable(dt, cdt *etable.Table) (mem, intr float64) {
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	plsz := ss.Hip.ECPool.Y * ss.Hip.ECPool.X
+	st := ss.wpvc * plsz // target pools, as in MemStats
+	ed := 2 * ss.wpvc * plsz
+	n := 0.0
+	ss.TestTable(dt, func() {
+		mem += ss.Mem
+		n++
+		if ss.Mem == 1 {
+			return
+		}
+		row := ss.TestEnv.Table.Idxs[ss.TestEnv.Trial.Cur]
+		ecout.UnitVals(&ss.TmpVals, "ActM")
+		out := ss.TmpVals[st:ed]
+		trg := dt.CellTensor("ECout", row).(*etensor.Float32).Values[st:ed]
+		cmp := cdt.CellTensor("ECout", row).(*etensor.Float32).Values[st:ed]
+		cc := metric.Cosine32(out, cmp)
+		if cc >= ss.Interf.IntrThr && cc > metric.Cosine32(out, trg) {
+			intr++
+		}
+	})
+	if n > 0 {
+		mem /= n
+		intr /= n
+	}
+	return
+}
+
+// InterfTest tests A-B and A-C, logging recall and intrusions to InterfLog
+func (ss *Sim) InterfTest() {
+	abm, abi := ss.InterfTestTable(ss.TestAB, ss.TestAC)
+	acm, aci := ss.InterfTestTable(ss.TestAC, ss.TestAB)
+	ss.LogInterf(ss.InterfLog, abm, acm, abi, aci)
+}
+
+//////////////////////////////////////////////
+//  InterfLog
+
+// LogInterf adds the results of one interference test to the InterfLog
+func (ss *Sim) LogInterf(dt *etable.Table, abm, acm, abi, aci float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("ExpType", row, ss.pfix)
+	dt.SetCellFloat("Lag", row, float64(ss.Interf.Lag))
+	dt.SetCellFloat("RI", row, float64(ss.Interf.RI))
+	dt.SetCellFloat("AB Mem", row, abm)
+	dt.SetCellFloat("AC Mem", row, acm)
+	dt.SetCellFloat("AB IntrC", row, abi)
+	dt.SetCellFloat("AC IntrB", row, aci)
+
+	if ss.InterfPlot != nil {
+		ss.InterfPlot.GoUpdate()
+	}
+	if ss.InterfFile != nil {
+		if !ss.InterfHdrs {
+			dt.WriteCSVHeaders(ss.InterfFile, etable.Tab)
+			ss.InterfHdrs = true
+		}
+		dt.WriteCSVRow(ss.InterfFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigInterfLog(dt *etable.Table) {
+	dt.SetMetaData("name", "InterfLog")
+	dt.SetMetaData("desc", "Proactive / retroactive interference: A-B and A-C recall, and intrusions of C in A-B recall and of B in A-C recall")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"ExpType", etensor.STRING, nil, nil},
+		{"Lag", etensor.INT64, nil, nil},
+		{"RI", etensor.INT64, nil, nil},
+		{"AB Mem", etensor.FLOAT64, nil, nil},
+		{"AC Mem", etensor.FLOAT64, nil, nil},
+		{"AB IntrC", etensor.FLOAT64, nil, nil},
+		{"AC IntrB", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigInterfPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Interference Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("ExpType", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Lag", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("RI", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AB Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("AC Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("AB IntrC", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("AC IntrB", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
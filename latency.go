@@ -0,0 +1,109 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"github.com/emer/leabra/leabra"
+)
+
+// LatencyParams control the retrieval latency measures of each test trial,
+// taken from the settling dynamics over its cycles, as a model reaction time:
+//
+//	ECoutLat: first cycle at which the ECout target region meets the memory
+//	          criterion (miss and false alarm rates both below MemThr, as in
+//	          MemStats, on the current Act)
+//	CA3Lat:   first cycle of the first run of StableN cycles over which the
+//	          mean absolute change in CA3 Act stays below StableTol, once CA3
+//	          is active
+//
+// Latencies are NaN on trials where the criterion is never met.
+type LatencyParams struct {
+	StableTol float32 `min:"0" desc:"CA3 is stable when the mean absolute change in unit Act per cycle is below this"`
+	StableN   int     `min:"1" desc:"number of cycles in a row CA3 must be stable"`
+	ActMin    float32 `min:"0" desc:"minimum CA3 average Act for stability to count -- the silent layer at trial onset is not stable"`
+}
+
+func (lp *LatencyParams) Defaults() {
+	lp.StableTol = 0.002
+	lp.StableN = 5
+	lp.ActMin = 0.005
+}
+
+func (lp *LatencyParams) Update() {
+	if lp.StableN < 1 {
+		lp.StableN = 1
+	}
+}
+
+// LatencyCyc updates the retrieval latencies of the current test trial at
+// given cycle -- called from AlphaCyc every test cycle
+func (ss *Sim) LatencyCyc(cyc int) {
+	lp := &ss.Latency
+	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
+	nn := len(ca3.Neurons)
+	if cyc == 0 {
+		ss.TrlECoutLat = math.NaN()
+		ss.TrlCA3Lat = math.NaN()
+		ss.LatStableN = 0
+		if len(ss.LatCA3Prv) != nn {
+			ss.LatCA3Prv = make([]float32, nn)
+		}
+	}
+
+	if math.IsNaN(ss.TrlECoutLat) && ss.ECoutCompleted() {
+		ss.TrlECoutLat = float64(cyc)
+	}
+
+	chg := float32(0)
+	for ni := range ca3.Neurons {
+		act := ca3.Neurons[ni].Act
+		if cyc > 0 {
+			chg += float32(math.Abs(float64(act - ss.LatCA3Prv[ni])))
+		}
+		ss.LatCA3Prv[ni] = act
+	}
+	if !math.IsNaN(ss.TrlCA3Lat) || cyc == 0 {
+		return
+	}
+	if chg/float32(nn) < lp.StableTol && ca3.Pools[0].Inhib.Act.Avg >= lp.ActMin {
+		ss.LatStableN++
+		if ss.LatStableN >= lp.StableN {
+			ss.TrlCA3Lat = float64(cyc - lp.StableN + 1)
+		}
+	} else {
+		ss.LatStableN = 0
+	}
+}
+
+// ECoutCompleted returns whether the current ECout Act meets the memory
+// criterion over the MemStats target region
+func (ss *Sim) ECoutCompleted() bool {
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	acti, _ := ecout.UnitVarIdx("Act")
+	targi, _ := ecout.UnitVarIdx("Targ")
+	ind1, ind2 := ss.MemRange()
+	onN, offN := 0.0, 0.0
+	miss, fa := 0.0, 0.0
+	for ni := ind1; ni < ind2; ni++ {
+		act := ecout.UnitVal1D(acti, ni)
+		if ecout.UnitVal1D(targi, ni) < 0.5 {
+			offN++
+			if act > 0.5 {
+				fa++
+			}
+		} else {
+			onN++
+			if act < 0.5 {
+				miss++
+			}
+		}
+	}
+	if onN == 0 || offN == 0 {
+		return false
+	}
+	return miss/onN < ss.MemThr && fa/offN < ss.MemThr
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/leabra/leabra"
+)
+
+// LesionSpec specifies one lesion of a projection or layer, and when it applies.
+// Kind is one of:
+//
+//	NoLearn: freeze learning (Learn.Learn = false) -- layer: all receiving projections
+//	Scale:   multiply WtScale.Abs by Frac -- layer: all receiving projections
+//	Silence: turn off a random Frac of the layer's units (Frac >= 1 = whole layer off)
+type LesionSpec struct {
+	Name     string  `desc:"projection name (e.g., ECinToCA3) or layer name (e.g., DG)"`
+	Kind     string  `desc:"kind of lesion: NoLearn, Scale, or Silence"`
+	Frac     float32 `desc:"for Scale: weight scale multiplier; for Silence: proportion of units silenced"`
+	Study    bool    `desc:"apply during study (training) trials"`
+	Test     bool    `desc:"apply during test trials"`
+	Sessions []int   `desc:"TrainEnv epochs in which the lesion applies (study session i runs at epoch i, the test after it at epoch i+1) -- empty = all"`
+	PreTrain bool    `desc:"also apply during pretraining -- set for the legacy no-learning switches, which always applied"`
+}
+
+// Active returns true if the lesion applies for given train / test mode and epoch
+func (ls *LesionSpec) Active(train bool, epc int) bool {
+	if train && !ls.Study || !train && !ls.Test {
+		return false
+	}
+	if len(ls.Sessions) == 0 {
+		return true
+	}
+	for _, s := range ls.Sessions {
+		if s == epc {
+			return true
+		}
+	}
+	return false
+}
+
+// Targets returns true if the lesion targets given projection, directly or
+// through its receiving layer
+func (ls *LesionSpec) Targets(pj *leabra.Prjn) bool {
+	return ls.Name == pj.Name() || ls.Name == pj.Recv.Name()
+}
+
+// LesionParams hold the lesion specs applied to the network on each trial.
+// Lesions do not apply during pretraining, unless PreTrain is set.
+type LesionParams struct {
+	Spec    string       `desc:"lesions as a semicolon-separated list of Name:Kind[:Frac[:When[:Sessions]]], where When is study, test, or both (default) and Sessions is a comma-separated list of epochs -- e.g., CA3ToCA1:NoLearn;DG:Silence:.5:test;ECinToCA3:Scale:.5:study:1,2"`
+	Lesions []LesionSpec `desc:"lesions in addition to those in Spec"`
+}
+
+func (lp *LesionParams) Defaults() {
+	lp.Lesions = nil
+}
+
+func (lp *LesionParams) Update() {
+	if _, err := ParseLesions(lp.Spec); err != nil {
+		log.Println(err)
+	}
+}
+
+// All returns all the lesion specs, from Lesions and Spec
+func (lp *LesionParams) All() []LesionSpec {
+	pls, _ := ParseLesions(lp.Spec)
+	return append(append([]LesionSpec{}, lp.Lesions...), pls...)
+}
+
+// ParseLesions parses lesion specs in the LesionParams.Spec format
+func ParseLesions(spec string) ([]LesionSpec, error) {
+	var lss []LesionSpec
+	for _, s := range strings.Split(spec, ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		fs := strings.Split(s, ":")
+		if len(fs) < 2 {
+			return lss, fmt.Errorf("ParseLesions: need at least Name:Kind in %q", s)
+		}
+		ls := LesionSpec{Name: fs[0], Kind: fs[1], Frac: 1, Study: true, Test: true}
+		switch ls.Kind {
+		case "NoLearn", "Scale", "Silence":
+		default:
+			return lss, fmt.Errorf("ParseLesions: unknown Kind %q in %q", ls.Kind, s)
+		}
+		if len(fs) > 2 && fs[2] != "" {
+			f, err := strconv.ParseFloat(fs[2], 32)
+			if err != nil {
+				return lss, fmt.Errorf("ParseLesions: bad Frac in %q", s)
+			}
+			ls.Frac = float32(f)
+		}
+		if len(fs) > 3 {
+			switch fs[3] {
+			case "study":
+				ls.Test = false
+			case "test":
+				ls.Study = false
+			case "both", "":
+			default:
+				return lss, fmt.Errorf("ParseLesions: When must be study, test, or both in %q", s)
+			}
+		}
+		if len(fs) > 4 {
+			for _, e := range strings.Split(fs[4], ",") {
+				epc, err := strconv.Atoi(strings.TrimSpace(e))
+				if err != nil {
+					return lss, fmt.Errorf("ParseLesions: bad Sessions in %q", s)
+				}
+				ls.Sessions = append(ls.Sessions, epc)
+			}
+		}
+		lss = append(lss, ls)
+	}
+	return lss, nil
+}
+
+// LesionScale returns the product of the Scale lesions currently applied to
+// given projection -- used wherever AlphaCyc sets WtScale.Abs directly
+func (ss *Sim) LesionScale(pj *leabra.Prjn, train bool) float32 {
+	sc := float32(1)
+	epc := ss.TrainEnv.Epoch.Cur
+	for _, ls := range ss.Lesion.All() {
+		if ss.PreTraining && !ls.PreTrain {
+			continue
+		}
+		if ls.Kind == "Scale" && ls.Targets(pj) && ls.Active(train, epc) {
+			sc *= ls.Frac
+		}
+	}
+	return sc
+}
+
+// ApplyLesions applies the lesions active for this trial, and returns a
+// function that restores the network to its unlesioned state.
+// Called at the start of AlphaCyc.
+func (ss *Sim) ApplyLesions(train bool) func() {
+	var restore []func()
+	undo := func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+	}
+	epc := ss.TrainEnv.Epoch.Cur
+	for _, ls := range ss.Lesion.All() {
+		if ss.PreTraining && !ls.PreTrain || !ls.Active(train, epc) {
+			continue
+		}
+		switch ls.Kind {
+		case "NoLearn", "Scale":
+			found := false
+			for _, pj := range ss.Prjns() {
+				if !ls.Targets(pj) {
+					continue
+				}
+				found = true
+				pj := pj
+				if ls.Kind == "NoLearn" {
+					sv := pj.Learn.Learn
+					pj.Learn.Learn = false
+					restore = append(restore, func() { pj.Learn.Learn = sv })
+				} else {
+					sv := pj.WtScale.Abs
+					pj.WtScale.Abs *= ls.Frac
+					restore = append(restore, func() { pj.WtScale.Abs = sv })
+				}
+			}
+			if !found {
+				log.Printf("ApplyLesions: projection or layer not found: %s\n", ls.Name)
+			}
+		case "Silence":
+			lyi := ss.Net.LayerByName(ls.Name)
+			if lyi == nil {
+				log.Printf("ApplyLesions: Silence needs a layer, not found: %s\n", ls.Name)
+				continue
+			}
+			ly := lyi.(leabra.LeabraLayer).AsLeabra()
+			if ls.Frac >= 1 {
+				sv := ly.Off
+				ly.Off = true
+				restore = append(restore, func() { ly.Off = sv })
+				continue
+			}
+			// same units every trial within a run, as for a real lesion
+			rnd := rand.New(rand.NewSource(int64(ss.TrainEnv.Run.Cur)))
+			perm := rnd.Perm(len(ly.Neurons))
+			ns := perm[:int(ls.Frac*float32(len(ly.Neurons))+0.5)]
+			var off []int
+			for _, ni := range ns {
+				nrn := &ly.Neurons[ni]
+				if !nrn.IsOff() {
+					nrn.SetFlag(leabra.NeurOff)
+					off = append(off, ni)
+				}
+			}
+			restore = append(restore, func() {
+				for _, ni := range off {
+					ly.Neurons[ni].ClearFlag(leabra.NeurOff)
+				}
+			})
+		}
+	}
+	return undo
+}
+
+// LegacyLesions returns NoLearn lesions for the original no-learning switches
+// (ECtoDGnl, ECtoCA3nl, ECtoCA1nl, CA3toCA1nl, CA3toCA3nl)
+func (ss *Sim) LegacyLesions() []LesionSpec {
+	var lss []LesionSpec
+	nls := []struct {
+		nl int
+		pj string
+	}{
+		{ss.ECtoDGnl, "ECinToDG"},
+		{ss.ECtoCA3nl, "ECinToCA3"},
+		{ss.ECtoCA1nl, "ECinToCA1"},
+		{ss.CA3toCA1nl, "CA3ToCA1"},
+		{ss.CA3toCA3nl, "CA3ToCA3"},
+	}
+	for _, nl := range nls {
+		if nl.nl == 1 {
+			lss = append(lss, LesionSpec{Name: nl.pj, Kind: "NoLearn", Frac: 1, Study: true, Test: true, PreTrain: true})
+		}
+	}
+	return lss
+}
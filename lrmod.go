@@ -0,0 +1,152 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/minmax"
+)
+
+// PrjnLrMod modulates the learning rate of one projection by a per-trial
+// error / novelty signal.  Signal is one of:
+//
+//	CA3ED14:   CA3 Q1 vs. plus-phase activity difference (TrlEDCA3)
+//	ECoutED34: ECout minus vs. plus-phase activity difference (TrlEDECout)
+//	CosDiff:   1 - ECout CosDiff, i.e., the cosine error
+type PrjnLrMod struct {
+	Prjn   string     `desc:"projection name, e.g., ECinToCA3"`
+	Signal string     `desc:"error signal: CA3ED14, ECoutED34, or CosDiff"`
+	Range  minmax.F32 `desc:"range of the signal mapped onto 0..1 (clipped)"`
+	Base   float32    `desc:"learning rate multiplier at the bottom of the range"`
+	Gain   float32    `desc:"exponent of the gain curve on the normalized signal: 1 = linear, > 1 = accelerating, < 1 = saturating"`
+	Invert bool       `desc:"multiplier decreases with the signal (familiarity-gated) instead of increasing"`
+}
+
+// Mult returns the learning rate multiplier for given signal value
+func (pm *PrjnLrMod) Mult(sig float32) float32 {
+	var f float32
+	switch {
+	case sig <= pm.Range.Min:
+		f = 0
+	case sig >= pm.Range.Max:
+		f = 1
+	default:
+		f = pm.Range.NormVal(sig)
+	}
+	if pm.Invert {
+		f = 1 - f
+	}
+	if pm.Gain != 1 {
+		f = float32(math.Pow(float64(f), float64(pm.Gain)))
+	}
+	return pm.Base + f*(1-pm.Base)
+}
+
+// PrjnLrModParams hold the per-projection learning rate modulators, which
+// are combined with any global ErrLrMod and ACh multipliers
+type PrjnLrModParams struct {
+	On   bool        `desc:"apply the per-projection learning rate modulators"`
+	Spec string      `desc:"modulators as a semicolon-separated list of Prjn:Signal:Min:Max[:Base[:Gain[:inv]]] -- e.g., ECinToCA3:CA3ED14:.65:1.02:.01;CA3ToCA1:ECoutED34:0:.5:.2:2"`
+	Mods []PrjnLrMod `desc:"modulators in addition to those in Spec"`
+}
+
+func (pl *PrjnLrModParams) Defaults() {
+	pl.Mods = nil
+}
+
+func (pl *PrjnLrModParams) Update() {
+	if _, err := ParsePrjnLrMods(pl.Spec); err != nil {
+		log.Println(err)
+	}
+}
+
+// All returns all the modulators, from Mods and Spec
+func (pl *PrjnLrModParams) All() []PrjnLrMod {
+	if !pl.On {
+		return nil
+	}
+	pms, _ := ParsePrjnLrMods(pl.Spec)
+	return append(append([]PrjnLrMod{}, pl.Mods...), pms...)
+}
+
+// ParsePrjnLrMods parses modulators in the PrjnLrModParams.Spec format
+func ParsePrjnLrMods(spec string) ([]PrjnLrMod, error) {
+	var pms []PrjnLrMod
+	for _, s := range strings.Split(spec, ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		fs := strings.Split(s, ":")
+		if len(fs) < 4 {
+			return pms, fmt.Errorf("ParsePrjnLrMods: need at least Prjn:Signal:Min:Max in %q", s)
+		}
+		pm := PrjnLrMod{Prjn: fs[0], Signal: fs[1], Base: 0, Gain: 1}
+		switch pm.Signal {
+		case "CA3ED14", "ECoutED34", "CosDiff":
+		default:
+			return pms, fmt.Errorf("ParsePrjnLrMods: unknown Signal %q in %q", pm.Signal, s)
+		}
+		var vals [4]float32
+		for i := 2; i < len(fs) && i < 6; i++ {
+			v, err := strconv.ParseFloat(fs[i], 32)
+			if err != nil {
+				return pms, fmt.Errorf("ParsePrjnLrMods: bad number %q in %q", fs[i], s)
+			}
+			vals[i-2] = float32(v)
+		}
+		pm.Range.Set(vals[0], vals[1])
+		if len(fs) > 4 {
+			pm.Base = vals[2]
+		}
+		if len(fs) > 5 {
+			pm.Gain = vals[3]
+		}
+		if len(fs) > 6 {
+			pm.Invert = fs[6] == "inv"
+		}
+		pms = append(pms, pm)
+	}
+	return pms, nil
+}
+
+// LrModSignal returns the current value of given signal
+func (ss *Sim) LrModSignal(sig string) float32 {
+	switch sig {
+	case "CA3ED14":
+		return ss.TrlEDCA3
+	case "ECoutED34":
+		return ss.TrlEDECout
+	case "CosDiff":
+		return float32(1 - ss.TrlCosDiff)
+	}
+	return 0
+}
+
+// PrjnLrateMult applies the per-projection modulators for this trial, on top
+// of the global learning rate multiplier already set by Net.LrateMult,
+// recording the multipliers in LrModVals.  Called in AlphaCyc before DWt.
+func (ss *Sim) PrjnLrateMult() {
+	pms := ss.PrjnLrMod.All()
+	if len(ss.LrModVals) != len(pms) {
+		ss.LrModVals = make([]float32, len(pms))
+	}
+	for i := range pms {
+		pm := &pms[i]
+		m := pm.Mult(ss.LrModSignal(pm.Signal))
+		ss.LrModVals[i] = m
+		pj := ss.PrjnByName(pm.Prjn)
+		if pj == nil {
+			log.Printf("PrjnLrateMult: projection not found: %s\n", pm.Prjn)
+			continue
+		}
+		pj.Learn.Lrate *= m
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// MSTParams control the mnemonic similarity task: after each test, studied
+// cues (Old), lures sharing a graded proportion of active bits with the
+// studied cues (Sim<pct>), and unrelated lures (New, the lA* items) are
+// presented in the test context.  Each response is scored from recall of the
+// studied associate in ECout: "old" if it is remembered (Mem), "similar" if
+// at least SimThr of its completion bits are recalled, and "new" otherwise.
+type MSTParams struct {
+	On       bool      `desc:"run the mnemonic similarity test after each test (command line)"`
+	Overlaps []float32 `desc:"lure similarity levels: proportion of active bits shared with the studied cue, within each pool"`
+	SimThr   float64   `desc:"minimum proportion of completion bits recalled (1 - TrgOnWasOff) for a \"similar\" response, when not remembered as old"`
+}
+
+func (mp *MSTParams) Defaults() {
+	mp.Overlaps = []float32{.1, .3, .6}
+	mp.SimThr = 0.5
+}
+
+func (mp *MSTParams) Update() {
+}
+
+// MSTCond returns the condition name for lures at given overlap
+func MSTCond(ovl float32) string {
+	return fmt.Sprintf("Sim%d", int(ovl*100+.5))
+}
+
+// ConfigMSTPats makes the MST lure vocabs, as copies of the A1-A4 cue vocabs
+// at each of the MST.Overlaps, and the TestMST patterns -- called at the end
+// of ConfigPats if MST is on, as the lures draw on the global random numbers,
+// and otherwise by MSTTest when it is first run (e.g., from the toolbar)
+func (ss *Sim) ConfigMSTPats() {
+	plsz := ss.Hip.ECPool.Y * ss.Hip.ECPool.X
+	pats := func(nm, a, b string) *etable.Table {
+		dt := &etable.Table{}
+		ss.TestPats(dt, nm, a, b)
+		return dt
+	}
+
+	ss.TestMST = pats("Old", "A", "B")
+	for _, ovl := range ss.MST.Overlaps {
+		cnd := MSTCond(ovl)
+		for i := 1; i <= 4; i++ {
+			nm := fmt.Sprintf("m%s_A%d", cnd, i)
+			lv, _ := patgen.AddVocabClone(ss.PoolVocab, nm, "A"+strconv.Itoa(i))
+			OverlapFlip(lv.Values, plsz, ovl)
+		}
+		// the target is the studied associate of the original cue
+		ss.TestMST.AppendRows(pats(cnd, "m"+cnd+"_A", "B"))
+	}
+	ss.TestMST.AppendRows(pats("New", "lA", "lB"))
+}
+
+// MSTResp returns the response to the current MST trial: old, similar or new
+func (ss *Sim) MSTResp() string {
+	switch {
+	case ss.Mem == 1:
+		return "old"
+	case 1-ss.TrgOnWasOffCmp >= ss.MST.SimThr:
+		return "similar"
+	}
+	return "new"
+}
+
+// MSTTest runs the mnemonic similarity test through all the TestMST items,
+// without learning, and logs the response proportions for each condition
+// and the lure discrimination index for each similarity level to MSTLog
+func (ss *Sim) MSTTest() {
+	if ss.TestMST.Rows == 0 {
+		ss.ConfigMSTPats()
+	}
+	resps := map[string]map[string]float64{}
+	var conds []string
+	ss.TestTable(ss.TestMST, func() {
+		cnd := strings.Split(ss.TestEnv.TrialName.Cur, "_")[0]
+		if _, has := resps[cnd]; !has {
+			resps[cnd] = map[string]float64{}
+			conds = append(conds, cnd)
+		}
+		resps[cnd][ss.MSTResp()]++
+		resps[cnd]["n"]++
+	})
+
+	psim := func(cnd string) float64 {
+		r := resps[cnd]
+		if r == nil || r["n"] == 0 {
+			return 0
+		}
+		return r["similar"] / r["n"]
+	}
+	for _, cnd := range conds {
+		r := resps[cnd]
+		ldi := 0.0
+		if strings.HasPrefix(cnd, "Sim") {
+			ldi = psim(cnd) - psim("New")
+		}
+		ss.LogMST(ss.MSTLog, cnd, r["old"]/r["n"], r["similar"]/r["n"], r["new"]/r["n"], ldi)
+	}
+}
+
+//////////////////////////////////////////////
+//  MSTLog
+
+// LogMST adds the response proportions for one MST condition to the MSTLog
+func (ss *Sim) LogMST(dt *etable.Table, cnd string, pold, psim, pnew, ldi float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	ovl := 0.0
+	switch {
+	case cnd == "Old":
+		ovl = 1
+	case strings.HasPrefix(cnd, "Sim"):
+		pct, _ := strconv.Atoi(strings.TrimPrefix(cnd, "Sim"))
+		ovl = float64(pct) / 100
+	}
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("Cond", row, cnd)
+	dt.SetCellFloat("Overlap", row, ovl)
+	dt.SetCellFloat("POld", row, pold)
+	dt.SetCellFloat("PSimilar", row, psim)
+	dt.SetCellFloat("PNew", row, pnew)
+	dt.SetCellFloat("LDI", row, ldi)
+
+	if ss.MSTPlot != nil {
+		ss.MSTPlot.GoUpdate()
+	}
+	if ss.MSTFile != nil {
+		if !ss.MSTHdrs {
+			dt.WriteCSVHeaders(ss.MSTFile, etable.Tab)
+			ss.MSTHdrs = true
+		}
+		dt.WriteCSVRow(ss.MSTFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigMSTLog(dt *etable.Table) {
+	dt.SetMetaData("name", "MSTLog")
+	dt.SetMetaData("desc", "Mnemonic similarity test: old / similar / new response proportions and lure discrimination index per condition")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Cond", etensor.STRING, nil, nil},
+		{"Overlap", etensor.FLOAT64, nil, nil},
+		{"POld", etensor.FLOAT64, nil, nil},
+		{"PSimilar", etensor.FLOAT64, nil, nil},
+		{"PNew", etensor.FLOAT64, nil, nil},
+		{"LDI", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigMSTPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Mnemonic Similarity Plot"
+	plt.Params.XAxisCol = "Overlap"
+	plt.Params.LegendCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Cond", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Overlap", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("POld", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PSimilar", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PNew", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("LDI", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	return plt
+}
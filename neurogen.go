@@ -0,0 +1,127 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/leabra/leabra"
+)
+
+// DG neurogenesis: as drift time passes, DG units are replaced by newborn
+// units with fresh random ECinToDG and DGToCA3 weights, which learn faster
+// (DGYoungLr) until they mature (DGMature drift steps).  Controlled by
+// the DGTurnover, DGMature and DGYoungLr HipParams.
+
+// InitDGAge makes all DG units mature -- called at the start of each run
+func (ss *Sim) InitDGAge() {
+	dg := ss.Net.LayerByName("DG").(leabra.LeabraLayer).AsLeabra()
+	if len(ss.DGAge) != len(dg.Neurons) {
+		ss.DGAge = make([]float32, len(dg.Neurons))
+	}
+	for i := range ss.DGAge {
+		ss.DGAge[i] = ss.Hip.DGMature
+	}
+}
+
+// DGYoungMult returns the learning rate multiplier for a DG unit of given age
+func (hp *HipParams) DGYoungMult(age float32) float32 {
+	if age >= hp.DGMature || hp.DGMature <= 0 {
+		return 1
+	}
+	return 1 + (hp.DGYoungLr-1)*(1-age/hp.DGMature)
+}
+
+// Neurogenesis ages the DG units by t drift steps, and replaces each with
+// probability 1 - (1 - DGTurnover)^t.  Called from DecayPhase.
+func (ss *Sim) Neurogenesis(t float32) {
+	hp := &ss.Hip
+	if hp.DGTurnover <= 0 || t <= 0 {
+		return
+	}
+	dg := ss.Net.LayerByName("DG").(leabra.LeabraLayer).AsLeabra()
+	if len(ss.DGAge) != len(dg.Neurons) {
+		ss.InitDGAge()
+	}
+	pnew := 1 - math.Pow(float64(1-hp.DGTurnover), float64(t))
+	for ni := range ss.DGAge {
+		ss.DGAge[ni] += t
+		if rand.Float64() < pnew {
+			ss.NewDGUnit(dg, ni)
+		}
+	}
+}
+
+// NewDGUnit replaces DG unit ni with a newborn unit: initial activations and
+// running averages, fresh random weights with no pending DWt or momentum on
+// all its receiving and sending projections, and age 0
+func (ss *Sim) NewDGUnit(dg *leabra.Layer, ni int) {
+	nrn := &dg.Neurons[ni]
+	dg.Act.InitActs(nrn)
+	dg.Learn.InitActAvg(nrn) // per-unit part of Layer.InitActAvg
+	for _, p := range dg.RcvPrjns {
+		pj := p.(leabra.LeabraPrjn).AsLeabra()
+		nc := int(pj.RConN[ni])
+		st := int(pj.RConIdxSt[ni])
+		for ci := 0; ci < nc; ci++ {
+			NewSyn(p, int(pj.RSynIdx[st+ci]))
+		}
+	}
+	for _, p := range dg.SndPrjns {
+		pj := p.(leabra.LeabraPrjn).AsLeabra()
+		nc := int(pj.SConN[ni])
+		st := int(pj.SConIdxSt[ni])
+		for ci := 0; ci < nc; ci++ {
+			NewSyn(p, st+ci)
+		}
+	}
+	ss.DGAge[ni] = 0
+}
+
+// NewSyn gives synapse si (index into Syns) of projection p a fresh random
+// weight, with no pending DWt or momentum, and resets any elapsed-time state
+// (e.g., cascade levels) to it
+func NewSyn(p emer.Prjn, si int) {
+	pj := p.(leabra.LeabraPrjn).AsLeabra()
+	sy := &pj.Syns[si]
+	pj.InitWtsSyn(sy)
+	sy.DWt = 0
+	sy.Moment = 0
+	if ep, ok := p.(Elapser); ok {
+		ep.InitElapseSyn(si)
+	}
+}
+
+// NeurogenDWt scales the DWt into young DG units by DGYoungMult, and ages
+// all DG units by one step -- called after Net.DWt for each study trial
+func (ss *Sim) NeurogenDWt() {
+	hp := &ss.Hip
+	if hp.DGTurnover <= 0 || ss.PreTraining {
+		return
+	}
+	dg := ss.Net.LayerByName("DG").(leabra.LeabraLayer).AsLeabra()
+	if len(ss.DGAge) != len(dg.Neurons) {
+		ss.InitDGAge()
+	}
+	for _, p := range dg.RcvPrjns {
+		pj := p.(leabra.LeabraPrjn).AsLeabra()
+		for ni, age := range ss.DGAge {
+			m := hp.DGYoungMult(age)
+			if m == 1 {
+				continue
+			}
+			nc := int(pj.RConN[ni])
+			st := int(pj.RConIdxSt[ni])
+			for ci := 0; ci < nc; ci++ {
+				pj.Syns[pj.RSynIdx[st+ci]].DWt *= m
+			}
+		}
+	}
+	for ni := range ss.DGAge {
+		ss.DGAge[ni]++
+	}
+}
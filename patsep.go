@@ -0,0 +1,205 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// PatSepParams control the pattern separation / completion harness, which
+// presents pairs of input patterns with graded overlap, without learning,
+// and measures the overlap of the resulting DG, CA3 and CA1 activity.
+type PatSepParams struct {
+	On       bool      `desc:"run the harness before training (command line)"`
+	Overlaps []float32 `desc:"input overlap levels: proportion of active bits shared within each pool"`
+	NPairs   int       `desc:"number of pattern pairs per overlap level -- base patterns are the TrainAB inputs, in order"`
+	Presets  []string  `desc:"HipParams size ParamSets to compare, each on a freshly built network -- empty = the current network as is (e.g., after training)"`
+	Layers   []string  `desc:"layers whose output overlap is measured"`
+}
+
+func (ps *PatSepParams) Defaults() {
+	ps.Overlaps = []float32{0, .1, .2, .3, .4, .5, .6, .7, .8, .9, 1}
+	ps.NPairs = 10
+	ps.Presets = []string{"SmallHip", "MedHip", "BigHip"}
+	ps.Layers = []string{"DG", "CA3", "CA1"}
+}
+
+func (ps *PatSepParams) Update() {
+}
+
+// OverlapPat returns a copy of pat in which each pool keeps the given proportion
+// of its active bits, with the rest moved to randomly chosen inactive units
+// of the same pool, so pool activity levels are preserved
+func (ss *Sim) OverlapPat(pat *etensor.Float32, ovl float32) *etensor.Float32 {
+	np := pat.Clone().(*etensor.Float32)
+	OverlapFlip(np.Values, ss.Hip.ECPool.Y*ss.Hip.ECPool.X, ovl)
+	return np
+}
+
+// OverlapFlip flips bits in vals, in place, so that each successive pool of
+// plsz values keeps the given proportion of its active bits, with the rest
+// moved to randomly chosen inactive units of the same pool
+func OverlapFlip(vals []float32, plsz int, ovl float32) {
+	for st := 0; st+plsz <= len(vals); st += plsz {
+		var on, off []int
+		for i := st; i < st+plsz; i++ {
+			if vals[i] > 0.5 {
+				on = append(on, i)
+			} else {
+				off = append(off, i)
+			}
+		}
+		k := int(math.Round(float64((1 - ovl) * float32(len(on)))))
+		if k > len(off) {
+			k = len(off)
+		}
+		rand.Shuffle(len(on), func(i, j int) { on[i], on[j] = on[j], on[i] })
+		rand.Shuffle(len(off), func(i, j int) { off[i], off[j] = off[j], off[i] })
+		for i := 0; i < k; i++ {
+			vals[on[i]] = 0
+			vals[off[i]] = 1
+		}
+	}
+}
+
+// PatSepActs presents input pattern pat without learning, and returns the
+// ActM of each of the PatSep.Layers
+func (ss *Sim) PatSepActs(pat *etensor.Float32) [][]float32 {
+	ss.Net.InitActs()
+	ss.Net.InitExt()
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	input.ApplyExt(pat)
+	ss.SettleCyc(false, false) // no learning
+	acts := make([][]float32, len(ss.PatSep.Layers))
+	for li, lnm := range ss.PatSep.Layers {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		ly.UnitVals(&acts[li], "ActM")
+	}
+	return acts
+}
+
+// PatSepCurves runs the pattern separation harness for each of the PatSep.Presets,
+// logging input vs. output overlap curves to PatSepLog
+func (ss *Sim) PatSepCurves() {
+	ps := &ss.PatSep
+	ss.PatSepLog.SetNumRows(0)
+	presets := ps.Presets
+	if len(presets) == 0 {
+		presets = []string{""}
+	}
+	svhip := ss.Hip
+	svps := ss.ParamSet
+	for _, pnm := range presets {
+		if pnm != "" {
+			ss.Hip = svhip
+			ss.ParamSet = pnm
+			ss.Init() // fresh network with this preset
+		}
+		ss.PatSepPreset(pnm)
+	}
+	if len(ps.Presets) > 0 {
+		ss.Hip = svhip
+		ss.ParamSet = svps
+		ss.Init()
+	}
+}
+
+// PatSepPreset runs all overlap levels on the current network, logging one
+// row per level under given preset name
+func (ss *Sim) PatSepPreset(pnm string) {
+	ps := &ss.PatSep
+	inps := ss.TrainAB.ColByName("Input").(*etensor.Float32)
+	nin := ss.TrainAB.Rows
+	if pnm == "" {
+		pnm = ss.ParamsName()
+	}
+	nly := len(ps.Layers)
+	for _, ovl := range ps.Overlaps {
+		inov := 0.0
+		outov := make([]float64, nly)
+		for pi := 0; pi < ps.NPairs; pi++ {
+			a := inps.SubSpace([]int{pi % nin}).(*etensor.Float32)
+			b := ss.OverlapPat(a, ovl)
+			inov += float64(metric.Cosine32(a.Values, b.Values))
+			aacts := ss.PatSepActs(a)
+			bacts := ss.PatSepActs(b)
+			for li := range ps.Layers {
+				outov[li] += float64(metric.Cosine32(aacts[li], bacts[li]))
+			}
+		}
+		np := float64(ps.NPairs)
+		for li := range outov {
+			outov[li] /= np
+		}
+		ss.LogPatSep(ss.PatSepLog, pnm, ovl, inov/np, outov)
+	}
+}
+
+//////////////////////////////////////////////
+//  PatSepLog
+
+// LogPatSep adds one overlap level of the pattern separation curves to the PatSepLog
+func (ss *Sim) LogPatSep(dt *etable.Table, pnm string, ovl float32, inov float64, outov []float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellString("Preset", row, pnm)
+	dt.SetCellFloat("Overlap", row, float64(ovl))
+	dt.SetCellFloat("InOverlap", row, inov)
+	for li, lnm := range ss.PatSep.Layers {
+		dt.SetCellFloat(lnm+" Overlap", row, outov[li])
+	}
+
+	if ss.PatSepPlot != nil {
+		ss.PatSepPlot.GoUpdate()
+	}
+	if ss.PatSepFile != nil {
+		if !ss.PatSepHdrs {
+			dt.WriteCSVHeaders(ss.PatSepFile, etable.Tab)
+			ss.PatSepHdrs = true
+		}
+		dt.WriteCSVRow(ss.PatSepFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigPatSepLog(dt *etable.Table) {
+	dt.SetMetaData("name", "PatSepLog")
+	dt.SetMetaData("desc", "Pattern separation / completion curves: input vs. output overlap per layer")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Preset", etensor.STRING, nil, nil},
+		{"Overlap", etensor.FLOAT64, nil, nil},
+		{"InOverlap", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.PatSep.Layers {
+		sch = append(sch, etable.Column{lnm + " Overlap", etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigPatSepPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Pattern Separation Plot"
+	plt.Params.XAxisCol = "InOverlap"
+	plt.Params.LegendCol = "Preset"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Preset", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Overlap", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("InOverlap", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	for _, lnm := range ss.PatSep.Layers {
+		plt.SetColParams(lnm+" Overlap", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
+	return plt
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// RDMParams control the export of model representational dissimilarity
+// matrices (RDMs) and their comparison with empirical (e.g., fMRI) RDMs.
+// After each test, the RDM of each of the LayStatNms layers is 1 - the
+// correlation SimMat of RepsAnalysis, over the AB test trials.
+//
+// RDM files, exported and loaded, are tab-separated text: a header line of
+// "Label" followed by the N item labels, then N lines of a label followed by
+// its N dissimilarities.  The model labels are the TrialName of the test
+// trials (e.g., TestAB_3) -- an empirical label matches a model label with
+// the same name or, if it is a bare integer, the trial of that item.
+//
+// Each empirical RDM (one file per region, named by its base file name) is
+// compared with each layer over the upper triangle of the aligned items, by
+// Spearman or Kendall (tau-b) rank correlation, with a permutation test over
+// the item labels of the empirical RDM.
+type RDMParams struct {
+	Export bool   `desc:"save the model RDM of each layer after each test (command line)"`
+	Files  string `desc:"comma-separated empirical RDM files, one per region, to compare with each layer after each test (command line)"`
+	Method string `desc:"rank correlation: Spearman or Kendall"`
+	NPerm  int    `desc:"number of label permutations for the permutation test -- 0 = none"`
+}
+
+func (rp *RDMParams) Defaults() {
+	rp.Method = "Spearman"
+	rp.NPerm = 1000
+}
+
+func (rp *RDMParams) Update() {
+	switch rp.Method {
+	case "Spearman", "Kendall":
+	default:
+		log.Printf("RDMParams: unknown Method: %s -- using Spearman\n", rp.Method)
+		rp.Method = "Spearman"
+	}
+}
+
+// On returns true if any RDMs are exported or compared
+func (rp *RDMParams) On() bool {
+	return rp.Export || rp.Files != ""
+}
+
+// FileList returns the empirical RDM files
+func (rp *RDMParams) FileList() []string {
+	return CommaList(rp.Files)
+}
+
+// RDM is a labeled representational dissimilarity matrix
+type RDM struct {
+	Name   string      `desc:"name of the layer or region"`
+	Labels []string    `desc:"item labels, for rows and columns"`
+	Dis    [][]float64 `desc:"dissimilarities"`
+}
+
+// ModelRDM returns the RDM of layer lnm from its RepsAnalysis SimMat
+func (ss *Sim) ModelRDM(lnm string) *RDM {
+	sm, ok := ss.SimMats[lnm]
+	if !ok || sm.Mat == nil {
+		return nil
+	}
+	n := len(sm.Rows)
+	rdm := &RDM{Name: lnm, Labels: append([]string{}, sm.Rows...), Dis: make([][]float64, n)}
+	for i := 0; i < n; i++ {
+		rdm.Dis[i] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			rdm.Dis[i][j] = 1 - sm.Mat.FloatVal([]int{i, j})
+		}
+	}
+	return rdm
+}
+
+// SaveRDM saves rdm to file fnm, in the RDM file format
+func SaveRDM(rdm *RDM, fnm string) error {
+	fp, err := os.Create(fnm)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	bw := bufio.NewWriter(fp)
+	fmt.Fprintf(bw, "Label\t%s\n", strings.Join(rdm.Labels, "\t"))
+	for i, lb := range rdm.Labels {
+		bw.WriteString(lb)
+		for _, d := range rdm.Dis[i] {
+			bw.WriteString("\t" + strconv.FormatFloat(d, 'g', LogPrec, 64))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// LoadRDM loads an RDM from file fnm, in the RDM file format, named by
+// the base file name
+func LoadRDM(fnm string) (*RDM, error) {
+	fp, err := os.Open(fnm)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	rdm := &RDM{Name: strings.TrimSuffix(filepath.Base(fnm), filepath.Ext(fnm))}
+	sc := bufio.NewScanner(fp)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" {
+			continue
+		}
+		fs := strings.Split(ln, "\t")
+		if rdm.Labels == nil {
+			rdm.Labels = fs[1:]
+			continue
+		}
+		if len(fs) != len(rdm.Labels)+1 {
+			return nil, fmt.Errorf("LoadRDM: %s: row %s has %d values, not %d", fnm, fs[0], len(fs)-1, len(rdm.Labels))
+		}
+		row := make([]float64, len(fs)-1)
+		for j, f := range fs[1:] {
+			row[j], err = strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("LoadRDM: %s: %v", fnm, err)
+			}
+		}
+		rdm.Dis = append(rdm.Dis, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(rdm.Dis) != len(rdm.Labels) {
+		return nil, fmt.Errorf("LoadRDM: %s: %d rows for %d labels", fnm, len(rdm.Dis), len(rdm.Labels))
+	}
+	return rdm, nil
+}
+
+// AlignRDMs returns the indexes into the model and empirical RDMs of the
+// items they share: by label, or by item for bare integer empirical labels
+func AlignRDMs(mdl, emp *RDM) (mi, ei []int) {
+	for e, lb := range emp.Labels {
+		for m, ml := range mdl.Labels {
+			if ml == lb {
+				mi = append(mi, m)
+				ei = append(ei, e)
+				break
+			}
+			if it, err := strconv.Atoi(lb); err == nil && TrialItem(ml) == it {
+				mi = append(mi, m)
+				ei = append(ei, e)
+				break
+			}
+		}
+	}
+	return
+}
+
+// UpperTri returns the upper triangle of dis, over the items idx
+func UpperTri(dis [][]float64, idx []int) []float64 {
+	var v []float64
+	for a := range idx {
+		for b := a + 1; b < len(idx); b++ {
+			v = append(v, dis[idx[a]][idx[b]])
+		}
+	}
+	return v
+}
+
+// Ranks returns the ranks of v, with ties given their mean rank
+func Ranks(v []float64) []float64 {
+	idx := make([]int, len(v))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return v[idx[a]] < v[idx[b]] })
+	rk := make([]float64, len(v))
+	for i := 0; i < len(idx); {
+		j := i + 1
+		for j < len(idx) && v[idx[j]] == v[idx[i]] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			rk[idx[k]] = float64(i+j-1)/2 + 1
+		}
+		i = j
+	}
+	return rk
+}
+
+// Spearman returns the Spearman rank correlation of a and b
+func Spearman(a, b []float64) float64 {
+	return metric.Correlation64(Ranks(a), Ranks(b))
+}
+
+// Kendall returns the Kendall tau-b rank correlation of a and b
+func Kendall(a, b []float64) float64 {
+	con, dis, ta, tb := 0.0, 0.0, 0.0, 0.0
+	for i := range a {
+		for j := i + 1; j < len(a); j++ {
+			da := a[i] - a[j]
+			db := b[i] - b[j]
+			switch {
+			case da == 0 && db == 0:
+			case da == 0:
+				ta++
+			case db == 0:
+				tb++
+			case da*db > 0:
+				con++
+			default:
+				dis++
+			}
+		}
+	}
+	den := math.Sqrt((con + dis + ta) * (con + dis + tb))
+	if den == 0 {
+		return 0
+	}
+	return (con - dis) / den
+}
+
+// RankCorr returns the rank correlation of a and b by Method
+func (rp *RDMParams) RankCorr(a, b []float64) float64 {
+	if rp.Method == "Kendall" {
+		return Kendall(a, b)
+	}
+	return Spearman(a, b)
+}
+
+// CompareRDMs returns the rank correlation of the model and empirical RDMs
+// over their shared items, the number of shared items, and the p value of
+// the correlation by permutation of the empirical item labels
+func (ss *Sim) CompareRDMs(mdl, emp *RDM) (r float64, n int, p float64) {
+	mi, ei := AlignRDMs(mdl, emp)
+	n = len(mi)
+	if n < 3 {
+		return 0, n, 1
+	}
+	mv := UpperTri(mdl.Dis, mi)
+	r = ss.RDM.RankCorr(mv, UpperTri(emp.Dis, ei))
+	if ss.RDM.NPerm <= 0 {
+		return r, n, math.NaN()
+	}
+	nge := 0
+	pi := make([]int, n)
+	rnd := rand.New(rand.NewSource(int64(ss.TrainEnv.Run.Cur))) // own source: leaves the model's random sequence unchanged
+	for k := 0; k < ss.RDM.NPerm; k++ {
+		for i, j := range rnd.Perm(n) {
+			pi[i] = ei[j]
+		}
+		if ss.RDM.RankCorr(mv, UpperTri(emp.Dis, pi)) >= r {
+			nge++
+		}
+	}
+	p = float64(1+nge) / float64(1+ss.RDM.NPerm)
+	return
+}
+
+// RDMTest exports the model RDM of each layer, and compares it with each of
+// the empirical RDMs, logging the results to RDMLog -- called after each test
+func (ss *Sim) RDMTest() {
+	var emps []*RDM
+	for _, fnm := range ss.RDM.FileList() {
+		emp, err := LoadRDM(fnm)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		emps = append(emps, emp)
+	}
+	epc := ss.TrainEnv.Epoch.Prv
+	for _, lnm := range ss.LayStatNms {
+		mdl := ss.ModelRDM(lnm)
+		if mdl == nil {
+			continue
+		}
+		if ss.RDM.Export {
+			fnm := ss.LogFileName(fmt.Sprintf("rdm_%s_%d", lnm, epc))
+			if err := SaveRDM(mdl, fnm); err != nil {
+				log.Println(err)
+			}
+		}
+		for _, emp := range emps {
+			r, n, p := ss.CompareRDMs(mdl, emp)
+			ss.LogRDM(ss.RDMLog, lnm, emp.Name, n, r, p)
+		}
+	}
+}
+
+//////////////////////////////////////////////
+//  RDMLog
+
+// LogRDM adds the comparison of one layer and region to the RDMLog
+func (ss *Sim) LogRDM(dt *etable.Table, lnm, region string, n int, r, p float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("Layer", row, lnm)
+	dt.SetCellString("Region", row, region)
+	dt.SetCellString("Method", row, ss.RDM.Method)
+	dt.SetCellFloat("NItems", row, float64(n))
+	dt.SetCellFloat("R", row, r)
+	dt.SetCellFloat("P", row, p)
+
+	if ss.RDMPlot != nil {
+		ss.RDMPlot.GoUpdate()
+	}
+	if ss.RDMFile != nil {
+		if !ss.RDMHdrs {
+			dt.WriteCSVHeaders(ss.RDMFile, etable.Tab)
+			ss.RDMHdrs = true
+		}
+		dt.WriteCSVRow(ss.RDMFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigRDMLog(dt *etable.Table) {
+	dt.SetMetaData("name", "RDMLog")
+	dt.SetMetaData("desc", "Rank correlation of model layer RDMs with empirical region RDMs, with permutation test p values")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Region", etensor.STRING, nil, nil},
+		{"Method", etensor.STRING, nil, nil},
+		{"NItems", etensor.INT64, nil, nil},
+		{"R", etensor.FLOAT64, nil, nil},
+		{"P", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigRDMPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus RDM Comparison Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.Params.LegendCol = "Layer"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Layer", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Region", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Method", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NItems", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("R", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("P", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const tol = 1e-9
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		v    []float64
+		want []float64
+	}{
+		{[]float64{}, []float64{}},
+		{[]float64{.3, .1, .2}, []float64{3, 1, 2}},
+		{[]float64{3, 1, 3, 2}, []float64{3.5, 1, 3.5, 2}},
+		{[]float64{5, 5, 5}, []float64{2, 2, 2}},
+	}
+	for _, tt := range tests {
+		if got := Ranks(tt.v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ranks(%v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRankCorrs(t *testing.T) {
+	tests := []struct {
+		a, b              []float64
+		spearman, kendall float64
+	}{
+		{[]float64{1, 2, 3, 4}, []float64{10, 20, 30, 40}, 1, 1},
+		{[]float64{1, 2, 3, 4}, []float64{4, 3, 2, 1}, -1, -1},
+		{[]float64{1, 2, 3, 4}, []float64{1, 4, 9, 16}, 1, 1}, // monotone, not linear
+		{[]float64{1, 2, 2, 3}, []float64{1, 2, 3, 4}, 4.5 / math.Sqrt(22.5), 5 / math.Sqrt(30)},
+	}
+	for _, tt := range tests {
+		if got := Spearman(tt.a, tt.b); math.Abs(got-tt.spearman) > tol {
+			t.Errorf("Spearman(%v, %v) = %g, want %g", tt.a, tt.b, got, tt.spearman)
+		}
+		if got := Kendall(tt.a, tt.b); math.Abs(got-tt.kendall) > tol {
+			t.Errorf("Kendall(%v, %v) = %g, want %g", tt.a, tt.b, got, tt.kendall)
+		}
+	}
+	if got := Kendall([]float64{1, 1, 1}, []float64{1, 2, 3}); got != 0 {
+		t.Errorf("Kendall of constant a = %g, want 0", got)
+	}
+}
+
+func TestUpperTri(t *testing.T) {
+	dis := [][]float64{{0, 1, 2}, {10, 11, 12}, {20, 21, 22}}
+	if got, want := UpperTri(dis, []int{0, 1, 2}), []float64{1, 2, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UpperTri(0,1,2) = %v, want %v", got, want)
+	}
+	if got, want := UpperTri(dis, []int{0, 2, 1}), []float64{2, 1, 21}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UpperTri(0,2,1) = %v, want %v", got, want)
+	}
+	if got := UpperTri(dis, []int{1}); len(got) != 0 {
+		t.Errorf("UpperTri(1) = %v, want empty", got)
+	}
+}
+
+func TestAlignRDMs(t *testing.T) {
+	mdl := &RDM{Labels: []string{"TestAB_0", "TestAB_1", "TestAB_2"}}
+	emp := &RDM{Labels: []string{"2", "X", "TestAB_0", "7"}}
+	mi, ei := AlignRDMs(mdl, emp)
+	if want := []int{2, 0}; !reflect.DeepEqual(mi, want) {
+		t.Errorf("AlignRDMs model idxs = %v, want %v", mi, want)
+	}
+	if want := []int{0, 2}; !reflect.DeepEqual(ei, want) {
+		t.Errorf("AlignRDMs empirical idxs = %v, want %v", ei, want)
+	}
+}
+
+func TestSaveLoadRDM(t *testing.T) {
+	rdm := &RDM{
+		Name:   "rdm_CA3",
+		Labels: []string{"a", "b", "c"},
+		Dis:    [][]float64{{0, .25, 1}, {.25, 0, .5}, {1, .5, 0}},
+	}
+	fnm := filepath.Join(t.TempDir(), "rdm_CA3.tsv")
+	if err := SaveRDM(rdm, fnm); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadRDM(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rdm) {
+		t.Errorf("LoadRDM = %+v, want %+v", got, rdm)
+	}
+	if _, err := LoadRDM(filepath.Join(t.TempDir(), "none.tsv")); err == nil {
+		t.Error("LoadRDM of a missing file: no error")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// RepDriftParams control the representational drift analysis: at the end of
+// each run, the ActM snapshot of each item in each study session (its last
+// study trial of the session, kept in TrlStore) is compared with those of
+// every other session, for each of the LayStatNms layers.  Same-item and
+// different-item similarity are logged for each session pair, with the time
+// between the sessions in drift steps, as one tidy table.
+type RepDriftParams struct {
+	On     bool   `desc:"run the representational drift analysis at the end of each run, keeping all trials in TrlStore (command line)"`
+	Metric string `desc:"similarity metric: Correlation or Cosine"`
+}
+
+func (rp *RepDriftParams) Defaults() {
+	rp.Metric = "Correlation"
+}
+
+func (rp *RepDriftParams) Update() {
+	switch rp.Metric {
+	case "Correlation", "Cosine":
+	default:
+		log.Printf("RepDriftParams: unknown Metric: %s -- using Correlation\n", rp.Metric)
+		rp.Metric = "Correlation"
+	}
+}
+
+// Sim returns the similarity of a and b by Metric
+func (rp *RepDriftParams) Sim(a, b []float64) float64 {
+	if rp.Metric == "Cosine" {
+		return metric.Cosine64(a, b)
+	}
+	return metric.Correlation64(a, b)
+}
+
+// LastTrials returns the index of the last trial of each item in each
+// session, by session and item, for trials in the given order with the given
+// session (epoch) and item -- sessions at or past nepc are skipped
+func LastTrials(epcs, items []int, nepc int) map[int]map[int]int {
+	last := map[int]map[int]int{}
+	for t, epc := range epcs {
+		if epc < 0 || epc >= nepc {
+			continue
+		}
+		if last[epc] == nil {
+			last[epc] = map[int]int{}
+		}
+		last[epc][items[t]] = t // later trials replace earlier
+	}
+	return last
+}
+
+// SessionSnaps returns the TrlStore row of the last study trial of each item
+// in each session, by session and item
+func (ss *Sim) SessionSnaps() map[int]map[int]int {
+	dt := ss.TrlStore
+	rows := ss.StoreRows("Trn", ss.MaxEpcs)
+	epcs := make([]int, len(rows))
+	items := make([]int, len(rows))
+	for t, r := range rows {
+		epcs[t] = int(dt.CellFloat("Epoch", r))
+		items[t] = int(dt.CellFloat("Item", r))
+	}
+	snaps := LastTrials(epcs, items, ss.MaxEpcs)
+	for _, its := range snaps {
+		for it, t := range its {
+			its[it] = rows[t]
+		}
+	}
+	return snaps
+}
+
+// SnapPairSims returns the mean same-item and different-item similarity, by
+// sim, between the snapshots a and b of two sessions, by item (nil = none),
+// and the number of pairs of each.  If self, a and b are the same session,
+// and each pair of different items is counted once.
+func SnapPairSims(a, b [][]float64, self bool, sim func(x, y []float64) float64) (same, diff float64, ns, nd int) {
+	for i1, x := range a {
+		if x == nil {
+			continue
+		}
+		for i2, y := range b {
+			if y == nil || self && i2 <= i1 {
+				continue
+			}
+			if i1 == i2 {
+				same += sim(x, y)
+				ns++
+			} else {
+				diff += sim(x, y)
+				nd++
+			}
+		}
+	}
+	return MeanSim(same, ns), MeanSim(diff, nd), ns, nd
+}
+
+// RepDriftRun computes same-item and different-item similarity between the
+// snapshots of each pair of study sessions (including each session with
+// itself, for different items only), for each layer, and logs them to
+// RepDriftLog -- called at the end of each run
+func (ss *Sim) RepDriftRun() {
+	dt := ss.TrlStore
+	snaps := ss.SessionSnaps()
+	nitm := 0
+	for _, its := range snaps {
+		for it := range its {
+			if it >= nitm {
+				nitm = it + 1
+			}
+		}
+	}
+	for _, lnm := range ss.LayStatNms {
+		col := lnm + "ActM"
+		vals := make([][][]float64, ss.MaxEpcs) // snapshots by session and item
+		for s, its := range snaps {
+			vals[s] = make([][]float64, nitm)
+			for it, r := range its {
+				vals[s][it] = dt.CellTensor(col, r).(*etensor.Float64).Values
+			}
+		}
+		for s1 := 0; s1 < ss.MaxEpcs; s1++ {
+			for s2 := s1; s2 < ss.MaxEpcs; s2++ {
+				if vals[s1] == nil || vals[s2] == nil {
+					continue
+				}
+				same, diff, ns, nd := SnapPairSims(vals[s1], vals[s2], s1 == s2, ss.RepDrift.Sim)
+				lag := ss.StudyTime(s2, 0) - ss.StudyTime(s1, 0)
+				ss.LogRepDrift(ss.RepDriftLog, lnm, s1, s2, lag, same, diff, ns, nd)
+			}
+		}
+	}
+}
+
+// MeanSim returns the mean similarity of n pairs summing to sum,
+// or NaN when there are no pairs
+func MeanSim(sum float64, n int) float64 {
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+//////////////////////////////////////////////
+//  RepDriftLog
+
+// LogRepDrift adds the similarities of one layer and session pair to the RepDriftLog
+func (ss *Sim) LogRepDrift(dt *etable.Table, lnm string, s1, s2, lag int, same, diff float64, ns, nd int) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellString("Layer", row, lnm)
+	dt.SetCellString("Metric", row, ss.RepDrift.Metric)
+	dt.SetCellFloat("Sess1", row, float64(s1))
+	dt.SetCellFloat("Sess2", row, float64(s2))
+	dt.SetCellFloat("Lag", row, float64(lag))
+	dt.SetCellFloat("SameSim", row, same)
+	dt.SetCellFloat("DiffSim", row, diff)
+	dt.SetCellFloat("NSame", row, float64(ns))
+	dt.SetCellFloat("NDiff", row, float64(nd))
+
+	if ss.RepDriftPlot != nil {
+		ss.RepDriftPlot.GoUpdate()
+	}
+	if ss.RepDriftFile != nil {
+		if !ss.RepDriftHdrs {
+			dt.WriteCSVHeaders(ss.RepDriftFile, etable.Tab)
+			ss.RepDriftHdrs = true
+		}
+		dt.WriteCSVRow(ss.RepDriftFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigRepDriftLog(dt *etable.Table) {
+	dt.SetMetaData("name", "RepDriftLog")
+	dt.SetMetaData("desc", "Representational drift: same-item and different-item similarity of study session snapshots per layer, by the time between sessions (Lag, drift steps between session onsets)")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Metric", etensor.STRING, nil, nil},
+		{"Sess1", etensor.INT64, nil, nil},
+		{"Sess2", etensor.INT64, nil, nil},
+		{"Lag", etensor.INT64, nil, nil},
+		{"SameSim", etensor.FLOAT64, nil, nil},
+		{"DiffSim", etensor.FLOAT64, nil, nil},
+		{"NSame", etensor.INT64, nil, nil},
+		{"NDiff", etensor.INT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigRepDriftPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Representational Drift Plot"
+	plt.Params.XAxisCol = "Lag"
+	plt.Params.LegendCol = "Layer"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Layer", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Metric", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess1", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess2", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Lag", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SameSim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("DiffSim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("NSame", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NDiff", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	return plt
+}
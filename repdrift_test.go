@@ -0,0 +1,66 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMeanSim(t *testing.T) {
+	if got := MeanSim(0, 0); !math.IsNaN(got) {
+		t.Errorf("MeanSim(0, 0) = %g, want NaN", got)
+	}
+	if got := MeanSim(1.5, 3); got != 0.5 {
+		t.Errorf("MeanSim(1.5, 3) = %g, want 0.5", got)
+	}
+	if got := MeanSim(-2, 4); got != -0.5 {
+		t.Errorf("MeanSim(-2, 4) = %g, want -0.5", got)
+	}
+}
+
+func TestLastTrials(t *testing.T) {
+	epcs := []int{0, 0, 0, 1, 1, 2, 3}
+	items := []int{0, 1, 0, 1, 1, 2, 0}
+	got := LastTrials(epcs, items, 3)
+	want := map[int]map[int]int{0: {0: 2, 1: 1}, 1: {1: 4}, 2: {2: 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LastTrials = %v, want %v", got, want)
+	}
+}
+
+func TestSnapPairSims(t *testing.T) {
+	dot := func(x, y []float64) float64 {
+		d := 0.0
+		for i := range x {
+			d += x[i] * y[i]
+		}
+		return d
+	}
+	a := [][]float64{{1, 0}, {0, 1}, nil, {1, 1}}
+	b := [][]float64{{2, 0}, nil, {5, 5}, {1, 0}}
+	tests := []struct {
+		nm         string
+		a, b       [][]float64
+		self       bool
+		same, diff float64
+		ns, nd     int
+	}{
+		{"between", a, b, false, 1.5, 23.0 / 7, 2, 7},
+		{"within", a, a, true, math.NaN(), 2.0 / 3, 0, 3},
+		{"no shared items", [][]float64{{1}}, [][]float64{nil, {1}}, false, math.NaN(), 1, 0, 1},
+		{"empty", nil, b, false, math.NaN(), math.NaN(), 0, 0},
+	}
+	eq := func(x, y float64) bool {
+		return math.IsNaN(x) && math.IsNaN(y) || math.Abs(x-y) < 1e-12
+	}
+	for _, tt := range tests {
+		same, diff, ns, nd := SnapPairSims(tt.a, tt.b, tt.self, dot)
+		if !eq(same, tt.same) || !eq(diff, tt.diff) || ns != tt.ns || nd != tt.nd {
+			t.Errorf("SnapPairSims %s = %g, %g, %d, %d, want %g, %g, %d, %d", tt.nm, same, diff, ns, nd, tt.same, tt.diff, tt.ns, tt.nd)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand"
+	"strconv"
+
+	"github.com/emer/emergent/erand"
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/etable/split"
+	"github.com/emer/leabra/leabra"
+)
+
+// ReplayParams control offline replay / sleep phases that run in the gaps
+// between study sessions, where the network gets no external input (or
+// noise only) and CA3 is left to reactivate stored patterns on its own.
+type ReplayParams struct {
+	On        bool    `desc:"run offline replay phases in the gaps between study sessions"`
+	Gaps      []int   `desc:"which gaps get a replay phase (0 = gap after the first study session) -- empty = all gaps"`
+	Retention bool    `desc:"also run a replay phase in the retention interval before the final test"`
+	NTrials   int     `desc:"number of replay trials per phase (added to the PerFiller count)"`
+	PerFiller float32 `desc:"additional replay trials per drift step of the gap (fillers, testlag) -- scales replay with ISI length"`
+	InPctAct  float32 `desc:"proportion of Input units randomly turned on each replay trial -- 0 = no external input at all"`
+	CA3Noise  float64 `desc:"variance of Gaussian Ge noise in CA3 during replay, which drives spontaneous reactivation"`
+	Learn     bool    `desc:"learn (DWt, WtFmDWt) from the replayed patterns"`
+	MatchThr  float64 `desc:"minimum correlation between replayed CA3 ActM and a studied item's CA3 ActM to count as a replay of that item"`
+}
+
+func (rp *ReplayParams) Defaults() {
+	rp.NTrials = 0
+	rp.PerFiller = 0.25
+	rp.InPctAct = 0
+	rp.CA3Noise = 0.02
+	rp.Learn = true
+	rp.MatchThr = 0.5
+}
+
+func (rp *ReplayParams) Update() {
+}
+
+// HasGap returns true if given gap index gets a replay phase
+func (rp *ReplayParams) HasGap(gap int) bool {
+	if len(rp.Gaps) == 0 {
+		return true
+	}
+	for _, g := range rp.Gaps {
+		if g == gap {
+			return true
+		}
+	}
+	return false
+}
+
+// NReplay returns the number of replay trials for a gap of given drift length
+func (rp *ReplayParams) NReplay(gaplen int) int {
+	return rp.NTrials + int(rp.PerFiller*float32(gaplen))
+}
+
+// ReplayPhase runs the offline phase for the gap before study session epc
+// (epc == MaxEpcs is the retention interval before the final test).
+// Called at the epoch change in TrainTrial, after LogTrnEpc has recorded
+// the session that just ended.
+func (ss *Sim) ReplayPhase(epc int) {
+	rp := &ss.Replay
+	if !rp.On || epc <= 0 {
+		return
+	}
+	gap := epc - 1
+	if epc >= ss.MaxEpcs {
+		if !rp.Retention {
+			return
+		}
+	} else if !rp.HasGap(gap) {
+		return
+	}
+	n := rp.NReplay(ss.GapLen(epc))
+	if n <= 0 {
+		return
+	}
+
+	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	svnoise := ca3.Act.Noise
+	ca3.Act.Noise.Type = leabra.GeNoise
+	ca3.Act.Noise.Dist = erand.Gaussian
+	ca3.Act.Noise.Mean = 0
+	ca3.Act.Noise.Var = rp.CA3Noise
+	ss.ReplayItems()
+
+	nin := input.Shape().Len()
+	for trl := 0; trl < n; trl++ {
+		ss.Net.InitActs()
+		ss.Net.InitExt()
+		if rp.InPctAct > 0 {
+			if len(ss.TmpVals) != nin {
+				ss.TmpVals = make([]float32, nin)
+			}
+			for i := range ss.TmpVals {
+				ss.TmpVals[i] = 0
+				if rand.Float32() < rp.InPctAct {
+					ss.TmpVals[i] = 1
+				}
+			}
+			input.ApplyExt1D32(ss.TmpVals)
+		}
+		ss.SettleCyc(true, rp.Learn) // free-running settling, ECout not clamped
+		item, sim := ss.ReplayMatch()
+		ss.LogReplay(ss.ReplayLog, epc, gap, trl, item, sim)
+	}
+	ca3.Act.Noise = svnoise
+	ss.LogReplayCounts(gap)
+}
+
+// ReplayItems collects the CA3 ActM patterns of the items studied in the
+// session that just ended, from TrnTrlLog, as the reference for identifying
+// which item each replay trial reactivated.
+func (ss *Sim) ReplayItems() {
+	dt := ss.TrnTrlLog
+	ss.ReplayRefs = ss.ReplayRefs[:0]
+	ss.ReplayNms = ss.ReplayNms[:0]
+	for ri := 0; ri < dt.Rows; ri++ {
+		var vals []float64
+		dt.CellTensor("CA3ActM", ri).Floats(&vals)
+		ss.ReplayRefs = append(ss.ReplayRefs, vals)
+		ss.ReplayNms = append(ss.ReplayNms, dt.CellString("TrialName", ri))
+	}
+}
+
+// ReplayMatch returns the studied item whose CA3 pattern best matches the
+// current CA3 ActM, and the correlation -- item is "none" if below MatchThr
+func (ss *Sim) ReplayMatch() (string, float64) {
+	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
+	ca3.UnitVals(&ss.TmpVals, "ActM")
+	act := make([]float64, len(ss.TmpVals))
+	for i, v := range ss.TmpVals {
+		act[i] = float64(v)
+	}
+	best := "none"
+	bsim := -1.0
+	for i, ref := range ss.ReplayRefs {
+		if len(ref) != len(act) {
+			continue
+		}
+		sim := metric.Correlation64(act, ref)
+		if sim > bsim {
+			bsim = sim
+			if sim >= ss.Replay.MatchThr {
+				best = ss.ReplayNms[i]
+			}
+		}
+	}
+	return best, bsim
+}
+
+//////////////////////////////////////////////
+//  ReplayLog
+
+// LogReplay adds one replay trial to the ReplayLog
+func (ss *Sim) LogReplay(dt *etable.Table, epc, gap, trl int, item string, sim float64) {
+	if trl == 0 { // one phase at a time
+		dt.SetNumRows(0)
+	}
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("Gap", row, float64(gap))
+	dt.SetCellFloat("Trial", row, float64(trl))
+	dt.SetCellString("Item", row, item)
+	dt.SetCellFloat("Sim", row, sim)
+	for _, lnm := range ss.LayStatNms {
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		dt.SetCellFloat(ly.Nm+" ActM.Avg", row, float64(ly.Pools[0].ActM.Avg))
+	}
+
+	if ss.ReplayPlot != nil {
+		ss.ReplayPlot.GoUpdate()
+	}
+	if ss.ReplayFile != nil {
+		if !ss.ReplayHdrs {
+			dt.WriteCSVHeaders(ss.ReplayFile, etable.Tab)
+			ss.ReplayHdrs = true
+		}
+		dt.WriteCSVRow(ss.ReplayFile, row, etable.Tab)
+	}
+}
+
+// LogReplayCounts counts how often each item was replayed in the phase just
+// logged in ReplayLog, and appends the counts to ReplayCounts
+func (ss *Sim) LogReplayCounts(gap int) {
+	ix := etable.NewIdxView(ss.ReplayLog)
+	spl := split.GroupBy(ix, []string{"Item"})
+	split.Agg(spl, "Sim", agg.AggCount)
+	cnts := spl.AggsToTable(etable.ColNameOnly)
+
+	dt := ss.ReplayCounts
+	for ci := 0; ci < cnts.Rows; ci++ {
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+		dt.SetCellFloat("Gap", row, float64(gap))
+		dt.SetCellString("Item", row, cnts.CellString("Item", ci))
+		dt.SetCellFloat("Count", row, cnts.CellFloat("Sim", ci))
+		if ss.RplCntFile != nil {
+			if !ss.RplCntHdrs {
+				dt.WriteCSVHeaders(ss.RplCntFile, etable.Tab)
+				ss.RplCntHdrs = true
+			}
+			dt.WriteCSVRow(ss.RplCntFile, row, etable.Tab)
+		}
+	}
+}
+
+func (ss *Sim) ConfigReplayLog(dt *etable.Table) {
+	dt.SetMetaData("name", "ReplayLog")
+	dt.SetMetaData("desc", "Record of offline replay trials in the last replay phase")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Gap", etensor.INT64, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"Item", etensor.STRING, nil, nil},
+		{"Sim", etensor.FLOAT64, nil, nil},
+	}
+	for _, lnm := range ss.LayStatNms {
+		sch = append(sch, etable.Column{lnm + " ActM.Avg", etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigReplayCounts(dt *etable.Table) {
+	dt.SetMetaData("name", "ReplayCounts")
+	dt.SetMetaData("desc", "Number of times each item was replayed, per replay phase")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Gap", etensor.INT64, nil, nil},
+		{"Item", etensor.STRING, nil, nil},
+		{"Count", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigReplayPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Replay Plot"
+	plt.Params.XAxisCol = "Trial"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Gap", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Trial", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Item", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	for _, lnm := range ss.LayStatNms {
+		plt.SetColParams(lnm+" ActM.Avg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 0.5)
+	}
+	return plt
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// RIFParams control the retrieval-induced forgetting paradigm: the A-B items
+// are divided into NCats categories, whose items share the patterns of the
+// first CatPools cue pools.  After the last study session, the first half
+// of the items in the first half of the categories (Rp+) get PracReps rounds
+// of retrieval practice: cued with A alone, with learning from what is
+// retrieved -- the minus phase ECout is clamped as the plus phase.
+// The tests then compare the practiced items (Rp+), the unpracticed items of
+// the practiced categories (Rp-), and the items of the unpracticed
+// categories (Nrp) -- RIF is Nrp - Rp- recall.
+type RIFParams struct {
+	On       bool `desc:"use the retrieval-induced forgetting paradigm (command line)"`
+	NCats    int  `min:"2" desc:"number of categories -- ListSize items are divided evenly among them"`
+	CatPools int  `min:"1" max:"4" desc:"number of cue (A) pools shared by the items of a category"`
+	PracReps int  `min:"1" desc:"number of rounds of retrieval practice on each Rp+ item"`
+}
+
+func (rp *RIFParams) Defaults() {
+	rp.NCats = 4
+	rp.CatPools = 2
+	rp.PracReps = 3
+}
+
+func (rp *RIFParams) Update() {
+}
+
+// RIFPer returns the number of items per category
+func (ss *Sim) RIFPer() int {
+	if ss.RIF.NCats < 1 || ss.Pat.ListSize < ss.RIF.NCats {
+		return 1
+	}
+	return ss.Pat.ListSize / ss.RIF.NCats
+}
+
+// RIFCond returns the category and the condition of item idx: Rp+, Rp- or Nrp
+func (ss *Sim) RIFCond(idx int) (int, string) {
+	nper := ss.RIFPer()
+	cat := idx / nper
+	switch {
+	case cat >= ss.RIF.NCats/2:
+		return cat, "Nrp"
+	case idx%nper < nper/2:
+		return cat, "Rp+"
+	}
+	return cat, "Rp-"
+}
+
+// RIFCats gives the items of each category the same patterns in the first
+// CatPools cue (A) pools -- called in ConfigPats after the A vocabs are made
+func (ss *Sim) RIFCats(npats int, pctAct, minDiff float32) {
+	plY := ss.Hip.ECPool.Y
+	plX := ss.Hip.ECPool.X
+	for p := 1; p <= ss.RIF.CatPools && p <= 4; p++ {
+		cats, _ := patgen.AddVocabPermutedBinary(ss.PoolVocab, "rifcat", ss.RIF.NCats, plY, plX, pctAct, minDiff)
+		av := ss.PoolVocab["A"+strconv.Itoa(p)]
+		for i := 0; i < npats; i++ {
+			cat, _ := ss.RIFCond(i)
+			if cat >= ss.RIF.NCats {
+				cat = ss.RIF.NCats - 1
+			}
+			copy(av.SubSpace([]int{i}).(*etensor.Float32).Values, cats.SubSpace([]int{cat}).(*etensor.Float32).Values)
+		}
+	}
+}
+
+// RIFPractice runs retrieval practice on the Rp+ items, in the context of the
+// last study session, at the end of study (epc = MaxEpcs), before the
+// retention interval -- called at the end of each training epoch
+func (ss *Sim) RIFPractice(epc int) {
+	if !ss.RIF.On || epc != ss.MaxEpcs {
+		return
+	}
+	hp := &ss.Hip
+	var inp []string
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, "A"+strconv.Itoa(i))
+	}
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, "empty")
+	}
+	for i := 0; i < ss.cvcn*2; i++ {
+		inp = append(inp, SessionCtxt(ss.MaxEpcs-1, i))
+	}
+	dt := &etable.Table{}
+	patgen.InitPats(dt, "RIFPrac_", "RIF retrieval practice Pats", "Input", "ECout", ss.Pat.ListSize, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+
+	// cued as a test, with no ECout target: the retrieved minus phase ECout
+	// is clamped as the plus phase, in place of ECin
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	ss.ClampLay = "ECout"
+	unsums := ss.SaveTrlSums()
+	for rep := 0; rep < ss.RIF.PracReps; rep++ {
+		for ri := 0; ri < dt.Rows; ri++ {
+			if _, cnd := ss.RIFCond(ri); cnd != "Rp+" {
+				continue
+			}
+			ss.Net.InitActs()
+			ss.Net.InitExt()
+			input.ApplyExt(dt.CellTensor("Input", ri))
+			ss.AlphaCyc(true) // practice with learning
+		}
+	}
+	unsums()
+	ss.ClampLay = ""
+}
+
+// RIFTest tests all the A-B items, and logs the recall of each condition and
+// the CA3 overlap of each item with the practiced items of its category
+// (for Nrp, the items at the Rp+ positions) to RIFLog
+func (ss *Sim) RIFTest() {
+	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
+	var acts [][]float32
+	var mems []float64
+	ss.TestTable(ss.TestAB, func() {
+		var vals []float32
+		ca3.UnitVals(&vals, "ActM")
+		acts = append(acts, vals)
+		mems = append(mems, ss.Mem)
+	})
+
+	conds := []string{"Rp+", "Rp-", "Nrp"}
+	mem := make(map[string]float64)
+	ovl := make(map[string]float64)
+	nm := make(map[string]float64)
+	no := make(map[string]float64)
+	nper := ss.RIFPer()
+	for i := range acts {
+		cat, cnd := ss.RIFCond(i)
+		mem[cnd] += mems[i]
+		nm[cnd]++
+		if cnd == "Rp+" {
+			continue
+		}
+		for j := range acts {
+			jcat, _ := ss.RIFCond(j)
+			if j == i || jcat != cat || j%nper >= nper/2 {
+				continue
+			}
+			ovl[cnd] += float64(metric.Cosine32(acts[i], acts[j]))
+			no[cnd]++
+		}
+	}
+	for _, cnd := range conds {
+		if nm[cnd] > 0 {
+			mem[cnd] /= nm[cnd]
+		}
+		if no[cnd] > 0 {
+			ovl[cnd] /= no[cnd]
+		}
+	}
+	ss.LogRIF(ss.RIFLog, mem, ovl)
+}
+
+//////////////////////////////////////////////
+//  RIFLog
+
+// LogRIF adds the results of one retrieval-induced forgetting test to the RIFLog
+func (ss *Sim) LogRIF(dt *etable.Table, mem, ovl map[string]float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellFloat("Rp+ Mem", row, mem["Rp+"])
+	dt.SetCellFloat("Rp- Mem", row, mem["Rp-"])
+	dt.SetCellFloat("Nrp Mem", row, mem["Nrp"])
+	dt.SetCellFloat("RIF", row, mem["Nrp"]-mem["Rp-"])
+	dt.SetCellFloat("Rp- CA3Ovl", row, ovl["Rp-"])
+	dt.SetCellFloat("Nrp CA3Ovl", row, ovl["Nrp"])
+
+	if ss.RIFPlot != nil {
+		ss.RIFPlot.GoUpdate()
+	}
+	if ss.RIFFile != nil {
+		if !ss.RIFHdrs {
+			dt.WriteCSVHeaders(ss.RIFFile, etable.Tab)
+			ss.RIFHdrs = true
+		}
+		dt.WriteCSVRow(ss.RIFFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigRIFLog(dt *etable.Table) {
+	dt.SetMetaData("name", "RIFLog")
+	dt.SetMetaData("desc", "Retrieval-induced forgetting: recall of practiced (Rp+), unpracticed same-category (Rp-) and baseline (Nrp) items, and CA3 overlap with the practiced items")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Rp+ Mem", etensor.FLOAT64, nil, nil},
+		{"Rp- Mem", etensor.FLOAT64, nil, nil},
+		{"Nrp Mem", etensor.FLOAT64, nil, nil},
+		{"RIF", etensor.FLOAT64, nil, nil},
+		{"Rp- CA3Ovl", etensor.FLOAT64, nil, nil},
+		{"Nrp CA3Ovl", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigRIFPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Retrieval-Induced Forgetting Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Rp+ Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Rp- Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Nrp Mem", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("RIF", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("Rp- CA3Ovl", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Nrp CA3Ovl", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	return plt
+}
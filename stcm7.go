@@ -94,6 +94,9 @@ type HipParams struct {
 	ECPctAct     float32    `desc:"percent activation in EC pool"`
 	MossyDel     float32    `desc:"delta in mossy effective strength between minus and plus phase"`
 	MossyDelTest float32    `desc:"delta in mossy strength for testing (relative to base param)"`
+	DGTurnover   float32    `desc:"DG neurogenesis: proportion of DG units replaced by newborn units per drift step of elapsed time between sessions -- 0 = off"`
+	DGMature     float32    `desc:"drift steps (gap time plus study trials) for a newborn DG unit to mature"`
+	DGYoungLr    float32    `desc:"learning rate multiplier for newborn DG units, declining linearly to 1 at maturity"`
 }
 
 func (hp *HipParams) Update() {
@@ -127,6 +130,29 @@ type Sim struct {
 	Hip        HipParams         `desc:"hippocampus sizing parameters"`
 	Pat        PatParams         `desc:"parameters for the input patterns"`
 	ErrLrMod   ErrLrateModParams `desc:"parameters for the error lrn modulation"` //JWA
+	Replay     ReplayParams      `desc:"parameters for offline replay phases between study sessions"`
+	Decay      DecayParams       `desc:"parameters for synaptic decay over elapsed drift time"`
+	Cascade    CascadeParams     `desc:"parameters for multi-timescale cascade synapses"`
+	Lesion     LesionParams      `desc:"projection and layer lesions, and when they apply"`
+	Theta      ThetaParams       `desc:"theta-phase schedule for AlphaCyc"`
+	ACh        AChParams         `desc:"acetylcholine-like encoding / retrieval mode modulation"`
+	PrjnLrMod  PrjnLrModParams   `desc:"per-projection error-gated learning rate modulation"`
+	PatSep     PatSepParams      `desc:"parameters for the pattern separation / completion harness"`
+	MST        MSTParams         `desc:"parameters for the mnemonic similarity test"`
+	AssocInf   AssocInfParams    `desc:"parameters for the associative inference (A-B, B-C -> A-C) paradigm"`
+	RIF        RIFParams         `desc:"parameters for the retrieval-induced forgetting paradigm"`
+	DirForget  DirForgetParams   `desc:"parameters for the list-method directed forgetting paradigm"`
+	Filler     FillerParams      `desc:"parameters for filler-task learning in the gaps between study sessions"`
+	Interf     InterfParams      `desc:"parameters for the proactive / retroactive interference (A-B, A-C) experiments"`
+	Temporal   TemporalParams    `desc:"parameters for the temporal order and judgment of recency tests"`
+	Decode     DecodeParams      `desc:"parameters for the linear decoding of session and item from layer activity"`
+	RepDrift   RepDriftParams    `desc:"parameters for the representational drift analysis across study sessions"`
+	RDM        RDMParams         `desc:"parameters for model RDM export and comparison with empirical RDMs"`
+	BOLD       BOLDParams        `desc:"parameters for the synthetic BOLD time series of each layer"`
+	Latency    LatencyParams     `desc:"parameters for the retrieval latency of each test trial"`
+	Trace      TraceParams       `desc:"parameters for the test trace recorder"`
+	WtSnap     WtSnapParams      `desc:"parameters for weight snapshots and weight change analyses"`
+	ED         EDParams          `desc:"parameters for the error difference statistics of each layer"`
 	PoolVocab  patgen.Vocab      `view:"no-inline" desc:"pool patterns vocabulary"`
 	TrainAB    *etable.Table     `view:"no-inline" desc:"AB training patterns to use"`
 	TrainAB2   *etable.Table     `view:"no-inline" desc:"AB training patterns to use"`
@@ -143,6 +169,9 @@ type Sim struct {
 	TestACnc   *etable.Table     `view:"no-inline" desc:"AC testing patterns to use, no temp context"`
 	TestLure   *etable.Table     `view:"no-inline" desc:"Lure testing patterns to use, AB list"`
 	TestLurenc *etable.Table     `view:"no-inline" desc:"Lure testing patterns to use, no temp context"`
+	TestMST    *etable.Table     `view:"no-inline" desc:"mnemonic similarity test patterns: old cues, graded similarity lures, and new lures"`
+	TestBC     *etable.Table     `view:"no-inline" desc:"BC testing patterns, for associative inference"`
+	TestL2     *etable.Table     `view:"no-inline" desc:"list 2 testing patterns, for directed forgetting"`
 	//TestLureAC   *etable.Table            `view:"no-inline" desc:"Lure testing patterns to use, AC list"` //JWA
 	TrainAll         *etable.Table            `view:"no-inline" desc:"all training patterns -- for pretrain"`
 	TrnTrlLog        *etable.Table            `view:"no-inline" desc:"training trial-level log data"`
@@ -155,6 +184,24 @@ type Sim struct {
 	RunLog           *etable.Table            `view:"no-inline" desc:"summary log of each run"`
 	RunStats         *etable.Table            `view:"no-inline" desc:"aggregate stats on all runs"`
 	TstStats         *etable.Table            `view:"no-inline" desc:"testing stats"`
+	ReplayLog        *etable.Table            `view:"no-inline" desc:"offline replay trials in the last replay phase"`
+	ReplayCounts     *etable.Table            `view:"no-inline" desc:"number of replays of each item per replay phase"`
+	PatSepLog        *etable.Table            `view:"no-inline" desc:"pattern separation / completion curves"`
+	MSTLog           *etable.Table            `view:"no-inline" desc:"mnemonic similarity test responses per condition"`
+	AssocInfLog      *etable.Table            `view:"no-inline" desc:"associative inference test results"`
+	RIFLog           *etable.Table            `view:"no-inline" desc:"retrieval-induced forgetting test results"`
+	DirForgetLog     *etable.Table            `view:"no-inline" desc:"directed forgetting test results"`
+	InterfLog        *etable.Table            `view:"no-inline" desc:"interference test results: recall and intrusions"`
+	TemporalLog      *etable.Table            `view:"no-inline" desc:"temporal order and judgment of recency test results"`
+	TrlStore         *etable.Table            `view:"no-inline" desc:"layer ActM of each trial over all sessions of the run, for decoding and drift analyses"`
+	DecodeLog        *etable.Table            `view:"no-inline" desc:"decoding accuracy per layer"`
+	RepDriftLog      *etable.Table            `view:"no-inline" desc:"same- and different-item similarity between study sessions per layer, by lag"`
+	RDMLog           *etable.Table            `view:"no-inline" desc:"rank correlations of model layer RDMs with empirical region RDMs"`
+	BOLDLog          *etable.Table            `view:"no-inline" desc:"synthetic BOLD time series of each layer over the run"`
+	TraceLog         *etable.Table            `view:"no-inline" desc:"index of the test traces in the binary trace file"`
+	WtPoolLog        *etable.Table            `view:"no-inline" desc:"weight snapshot stats per pool"`
+	WtItemLog        *etable.Table            `view:"no-inline" desc:"weight change of the top synapses of each item"`
+	WtSimLog         *etable.Table            `view:"no-inline" desc:"similarity of item weight fingerprints between snapshots"`
 	TrainSimMats     *etable.Table            `view:"simmats for printing during training"`
 	SimMats          map[string]*simat.SimMat `view:"no-inline" desc:"similarity matrix results for layers"`
 	SimMatsQ2        map[string]*simat.SimMat `view:"no-inline" desc:"similarity matrix results for layers, Q2"`
@@ -163,6 +210,7 @@ type Sim struct {
 	Params           params.Sets              `view:"no-inline" desc:"full collection of param sets"`
 	ParamSet         string                   `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
 	Tag              string                   `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files, params)"`
+	Arch             string                   `desc:"architecture variant, selecting the learning rule per pathway (see ArchVariants) -- also applies the CA3CA1_<rule> and Arch_<name> ParamSets if present"`
 	MaxRuns          int                      `desc:"maximum number of model runs to perform"`
 	MaxEpcs          int                      `desc:"maximum number of epochs to run per model run"`
 	Cycs             int                      `desc:"# of alpha cycles / epoch"`                               //JWA
@@ -198,15 +246,15 @@ type Sim struct {
 	exptype          int                      `desc:"experiment type (e.g., fcurve)"`
 	interval         int                      `desc:"retention interval"`
 	targortemp       int                      `desc:"test the target (1, default) or temporal context (2)"`
-	ECtoDGnl         int                      `desc:"no learning from EC to DG"`
-	ECtoCA3nl        int                      `desc:"no learning from EC to CA3"`
-	ECtoCA1nl        int                      `desc:"no learning from EC to CA1"`
-	CA3toCA1nl       int                      `desc:"no learning from CA3 to CA1"`
-	CA3toCA3nl       int                      `desc:"no learning from CA3 to CA1"`
+	ECtoDGnl         int                      `desc:"no learning from EC to DG (adds a NoLearn lesion)"`
+	ECtoCA3nl        int                      `desc:"no learning from EC to CA3 (adds a NoLearn lesion)"`
+	ECtoCA1nl        int                      `desc:"no learning from EC to CA1 (adds a NoLearn lesion)"`
+	CA3toCA1nl       int                      `desc:"no learning from CA3 to CA1 (adds a NoLearn lesion)"`
+	CA3toCA3nl       int                      `desc:"no learning from CA3 to CA3 (adds a NoLearn lesion)"`
 	lratemulton      int                      `desc:"turn on / off lrate multiplier"`
 	spect_type       int                      `desc:"type of drift in temp pools"`
-	synap_decay      int                      `desc:"implement synaptic decay?"`
-	decay_rate       float64                  `desc:"decay rate (if decay on)"`
+	synap_decay      int                      `desc:"implement synaptic decay? (sets Decay.On)"`
+	decay_rate       float64                  `desc:"decay rate (if decay on) (sets Decay.Rate)"`
 	testlag          int                      `desc:"store testlag?"`
 	fillers          [5]int                   `desc:"fscale values"`
 	smithetal        int                      `desc:"smith et al decontextualization experiment if non-zero"`
@@ -226,10 +274,10 @@ type Sim struct {
 	TrlAvgSSE      float64 `inactive:"+" desc:"current trial's average sum squared error"`
 	TrlCosDiff     float64 `inactive:"+" desc:"current trial's cosine difference"`
 	TrlEDCA3       float32 `inactive:"+" desc:"use this to modify specifically CA3 in LRateMult"` //JWA added from here on!
-	DGED14         float64 `inactive:"+" desc:"err diffs"`
-	CA3ED14        float64 `inactive:"+" desc:"err diffs"`
-	CA1ED14        float64 `inactive:"+" desc:"err diffs"`
-	ECoutED34      float64 `inactive:"+" desc:"err diffs"`
+	TrlACh         float32 `inactive:"+" desc:"current ACh level: 1 = novel / encoding mode, 0 = familiar / retrieval mode"`
+	TrlEDECout     float32 `inactive:"+" desc:"current trial's ECout minus vs. plus phase activity difference (ED34)"`
+	TrlECoutLat    float64 `inactive:"+" desc:"current test trial's retrieval latency: cycle at which ECout met the memory criterion (NaN = never)"`
+	TrlCA3Lat      float64 `inactive:"+" desc:"current test trial's retrieval latency: cycle at which CA3 settled (NaN = never)"`
 
 	EpcSSE        float64 `inactive:"+" desc:"last epoch's total sum squared error"`
 	EpcAvgSSE     float64 `inactive:"+" desc:"last epoch's average sum squared error (average over trials, and over units within layer)"`
@@ -255,14 +303,86 @@ type Sim struct {
 	TstCycPlot   *eplot.Plot2D               `view:"-" desc:"the test-cycle plot"`
 	RunPlot      *eplot.Plot2D               `view:"-" desc:"the run plot"`
 	RunStatsPlot *eplot.Plot2D               `view:"-" desc:"the run stats plot"`
+	ReplayPlot   *eplot.Plot2D               `view:"-" desc:"the replay plot"`
+	PatSepPlot   *eplot.Plot2D               `view:"-" desc:"the pattern separation plot"`
+	MSTPlot      *eplot.Plot2D               `view:"-" desc:"the mnemonic similarity plot"`
+	AssocInfPlot *eplot.Plot2D               `view:"-" desc:"the associative inference plot"`
+	RIFPlot      *eplot.Plot2D               `view:"-" desc:"the retrieval-induced forgetting plot"`
+	DirFgtPlot   *eplot.Plot2D               `view:"-" desc:"the directed forgetting plot"`
+	InterfPlot   *eplot.Plot2D               `view:"-" desc:"the interference plot"`
+	TemporalPlot *eplot.Plot2D               `view:"-" desc:"the temporal memory plot"`
+	DecodePlot   *eplot.Plot2D               `view:"-" desc:"the decoding plot"`
+	RepDriftPlot *eplot.Plot2D               `view:"-" desc:"the representational drift plot"`
+	RDMPlot      *eplot.Plot2D               `view:"-" desc:"the RDM comparison plot"`
+	BOLDPlot     *eplot.Plot2D               `view:"-" desc:"the synthetic BOLD plot"`
+	WtPoolPlot   *eplot.Plot2D               `view:"-" desc:"the weight pool plot"`
+	WtItemPlot   *eplot.Plot2D               `view:"-" desc:"the weight item plot"`
+	WtSimPlot    *eplot.Plot2D               `view:"-" desc:"the weight similarity plot"`
 	TrnEpcFile   *os.File                    `view:"-" desc:"log file"`
 	TrnEpcHdrs   bool                        `view:"-" desc:"headers written"`
 	TstEpcFile   *os.File                    `view:"-" desc:"log file"`
 	TstEpcHdrs   bool                        `view:"-" desc:"headers written"`
 	RunFile      *os.File                    `view:"-" desc:"log file"`
+	ReplayFile   *os.File                    `view:"-" desc:"log file"`
+	ReplayHdrs   bool                        `view:"-" desc:"headers written"`
+	RplCntFile   *os.File                    `view:"-" desc:"log file"`
+	RplCntHdrs   bool                        `view:"-" desc:"headers written"`
+	PatSepFile   *os.File                    `view:"-" desc:"log file"`
+	PatSepHdrs   bool                        `view:"-" desc:"headers written"`
+	MSTFile      *os.File                    `view:"-" desc:"log file"`
+	MSTHdrs      bool                        `view:"-" desc:"headers written"`
+	AssocInfFile *os.File                    `view:"-" desc:"log file"`
+	AssocInfHdrs bool                        `view:"-" desc:"headers written"`
+	RIFFile      *os.File                    `view:"-" desc:"log file"`
+	RIFHdrs      bool                        `view:"-" desc:"headers written"`
+	DirFgtFile   *os.File                    `view:"-" desc:"log file"`
+	DirFgtHdrs   bool                        `view:"-" desc:"headers written"`
+	InterfFile   *os.File                    `view:"-" desc:"log file"`
+	InterfHdrs   bool                        `view:"-" desc:"headers written"`
+	TemporalFile *os.File                    `view:"-" desc:"log file"`
+	TemporalHdrs bool                        `view:"-" desc:"headers written"`
+	DecodeFile   *os.File                    `view:"-" desc:"log file"`
+	DecodeHdrs   bool                        `view:"-" desc:"headers written"`
+	RepDriftFile *os.File                    `view:"-" desc:"log file"`
+	RepDriftHdrs bool                        `view:"-" desc:"headers written"`
+	RDMFile      *os.File                    `view:"-" desc:"log file"`
+	RDMHdrs      bool                        `view:"-" desc:"headers written"`
+	BOLDFile     *os.File                    `view:"-" desc:"log file"`
+	BOLDHdrs     bool                        `view:"-" desc:"headers written"`
+	TraceFile    *os.File                    `view:"-" desc:"log file"`
+	TraceHdrs    bool                        `view:"-" desc:"headers written"`
+	TraceBin     *os.File                    `view:"-" desc:"binary trace file"`
+	TraceOff     int64                       `view:"-" desc:"bytes written to the binary trace file"`
+	WtPoolFile   *os.File                    `view:"-" desc:"log file"`
+	WtPoolHdrs   bool                        `view:"-" desc:"headers written"`
+	WtItemFile   *os.File                    `view:"-" desc:"log file"`
+	WtItemHdrs   bool                        `view:"-" desc:"headers written"`
+	WtSimFile    *os.File                    `view:"-" desc:"log file"`
+	WtSimHdrs    bool                        `view:"-" desc:"headers written"`
+	WtSnapFiles  map[string]*os.File         `view:"-" desc:"weight snapshot files, by projection"`
+	WtSnapHdrs   map[string]bool             `view:"-" desc:"headers written, by projection"`
+	WtSnapTbls   map[string]*etable.Table    `view:"-" desc:"one-row tables for writing weight snapshots, by projection"`
+	ReplayRefs   [][]float64                 `view:"-" desc:"CA3 ActM of studied items, for identifying replayed items"`
+	ReplayNms    []string                    `view:"-" desc:"names of studied items, for identifying replayed items"`
+	PreTraining  bool                        `view:"-" desc:"true during pretraining, when only PreTrain lesions apply"`
+	ThetaRel     map[string]float32          `view:"-" desc:"base WtScale.Rel of projections in the theta schedule, for the current trial"`
+	ClampLay     string                      `view:"-" desc:"if set, layer clamped onto ECout in place of the theta schedule Clamp layer -- ECout during retrieval practice, for its own minus phase as the plus phase"`
+	AChRel       [2]float32                  `view:"-" desc:"base WtScale.Rel of ECoutToECin and CA3ToCA3, for the current trial"`
+	LrModVals    []float32                   `view:"-" desc:"current trial's per-projection learning rate multipliers, in PrjnLrMod.All() order"`
+	DGAge        []float32                   `view:"-" desc:"age of each DG unit in drift steps, for neurogenesis"`
+	BOLDEvents   []BOLDEvent                 `view:"-" desc:"layer signals of each trial over the run, for the synthetic BOLD"`
+	BOLDTstN     int                         `view:"-" desc:"number of test trials recorded for the synthetic BOLD in this run, which delay all later trials"`
+	LatCA3Prv    []float32                   `view:"-" desc:"CA3 Act on the previous cycle, for the retrieval latency"`
+	LatStableN   int                         `view:"-" desc:"number of cycles in a row CA3 has been stable, for the retrieval latency"`
+	TraceTrl     bool                        `view:"-" desc:"true if the current test trial is traced"`
+	TraceCycs    int                         `view:"-" desc:"number of cycles traced on the current test trial"`
+	TraceBufs    map[string][]float32        `view:"-" desc:"traced unit values of the current test trial, by layer"`
+	WtSnapPrv    map[string][]float32        `view:"-" desc:"previous weight snapshot of each projection, for the weight changes"`
+	WtFPs        map[string][]*WtFPSet       `view:"-" desc:"item weight fingerprints of each snapshot, by projection"`
+	TrlEDs       map[string]float64          `view:"-" desc:"current trial's error differences, by ED column name"`
+	EDSums       map[string]float64          `view:"-" desc:"error differences summed over the training epoch, by ED column name"`
 	TmpVals      []float32                   `view:"-" desc:"temp slice for holding values -- prevent mem allocs"`
-	TmpValsDWt   []float32                   `view:"-" desc:"temp slice for holding dwt values -- prevent mem allocs"`                //JWA
-	TmpValsWtR   []float32                   `view:"-" desc:"temp slice for holding wt values from rec to ca3 -- prevent mem allocs"` //JWA
+	TmpValsDWt   []float32                   `view:"-" desc:"temp slice for holding dwt values -- prevent mem allocs"` //JWA
 	LayStatNms   []string                    `view:"-" desc:"names of layers to collect more detailed stats on (avg act, etc)"`
 	TstNms       []string                    `view:"-" desc:"names of test tables"`
 	SimMatStats  []string                    `view:"-" desc:"names of sim mat stats"`
@@ -331,6 +451,9 @@ func (ss *Sim) New() {
 	ss.TestACnc = &etable.Table{}
 	ss.TestLure = &etable.Table{}
 	ss.TestLurenc = &etable.Table{}
+	ss.TestMST = &etable.Table{}
+	ss.TestBC = &etable.Table{}
+	ss.TestL2 = &etable.Table{}
 	//ss.TestLureAC = &etable.Table{}
 	ss.TrainAll = &etable.Table{}
 	ss.TrnTrlLog = &etable.Table{}
@@ -342,6 +465,24 @@ func (ss *Sim) New() {
 	ss.TstCycLog = &etable.Table{}
 	ss.RunLog = &etable.Table{}
 	ss.RunStats = &etable.Table{}
+	ss.ReplayLog = &etable.Table{}
+	ss.ReplayCounts = &etable.Table{}
+	ss.PatSepLog = &etable.Table{}
+	ss.MSTLog = &etable.Table{}
+	ss.AssocInfLog = &etable.Table{}
+	ss.RIFLog = &etable.Table{}
+	ss.DirForgetLog = &etable.Table{}
+	ss.InterfLog = &etable.Table{}
+	ss.TemporalLog = &etable.Table{}
+	ss.TrlStore = &etable.Table{}
+	ss.DecodeLog = &etable.Table{}
+	ss.RepDriftLog = &etable.Table{}
+	ss.RDMLog = &etable.Table{}
+	ss.BOLDLog = &etable.Table{}
+	ss.TraceLog = &etable.Table{}
+	ss.WtPoolLog = &etable.Table{}
+	ss.WtItemLog = &etable.Table{}
+	ss.WtSimLog = &etable.Table{}
 	ss.SimMats = make(map[string]*simat.SimMat)
 	ss.SimMatsQ2 = make(map[string]*simat.SimMat)
 	ss.SimMatsLst = make(map[string]*simat.SimMat)
@@ -391,13 +532,49 @@ func (ss *Sim) New() {
 	ss.drifttypes = ss.cepeda_stop + 0 //experimental conditions
 	ss.runnum = 0
 	var nogui bool // JWA 2_18_21
+	var fillrate float64
+	var lag, ri int
+	var dfshift, tmplayer, decsrc, boldsig, tracelay, edpairs string
 	// DUPLICATE these flags up here so they can be used to read in file (otherwise it comes after read-in...)
 	if len(os.Args) > 1 {
 		flag.IntVar(&ss.expnum, "expnum", -1, "which specific experiment # to run")
 		flag.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this run")
+		flag.StringVar(&ss.Arch, "arch", ArchVariants[0].Name, "architecture variant, selecting the learning rule per pathway (see ArchVariants)")
 		flag.IntVar(&ss.MaxRuns, "runs", 2, "number of runs to do")
 		flag.IntVar(&ss.MaxEpcs, "epcs", 5, "number of epcs to do")
 		flag.BoolVar(&nogui, "nogui", true, "if not passing any other args and want to run nogui, use nogui")
+		flag.IntVar(&ss.synap_decay, "decay", ss.synap_decay, "if 1, apply synaptic decay over elapsed drift time (see Decay params)")
+		flag.BoolVar(&ss.Replay.On, "replay", false, "if true, run offline replay phases between study sessions (see Replay params)")
+		flag.BoolVar(&ss.Filler.On, "filler", false, "if true, learn new filler pairs in the gaps between study sessions (see Filler params)")
+		flag.Float64Var(&fillrate, "fillrate", 0.25, "filler trials per drift step of the gap, for -filler")
+		flag.BoolVar(&ss.Cascade.On, "cascade", false, "if true, use multi-timescale cascade synapses (see Cascade params)")
+		flag.StringVar(&ss.PrjnLrMod.Spec, "prjnlrmod", "", "per-projection lrate modulators as Prjn:Signal:Min:Max[:Base[:Gain[:inv]]];... (see PrjnLrMod params)")
+		flag.BoolVar(&ss.PatSep.On, "patsep", false, "if true, run the pattern separation harness before training (see PatSep params)")
+		flag.BoolVar(&ss.MST.On, "mst", false, "if true, run the mnemonic similarity test after each test (see MST params)")
+		flag.BoolVar(&ss.AssocInf.On, "associnf", false, "if true, use the associative inference paradigm: B-C sessions after A-B, testing A-C (see AssocInf params)")
+		flag.IntVar(&ss.AssocInf.Lag, "ailag", 1, "drift steps between the last A-B session and the first B-C session, for -associnf")
+		flag.StringVar(&ss.DirForget.Cue, "dfcue", "", "if Forget or Remember, use the directed forgetting paradigm with this cue between the lists (see DirForget params)")
+		flag.StringVar(&dfshift, "dfshift", "Jump", "context shift triggered by a Forget cue: Jump or Drift, for -dfcue")
+		flag.StringVar(&ss.Interf.Design, "interf", "", "if PI, RI or RIn, run that interference experiment: A-B, then A-C after -lag, testing both after -ri (see Interf params)")
+		flag.IntVar(&lag, "lag", 16, "drift steps between the last A-B session and the first A-C session, for -interf")
+		flag.IntVar(&ri, "ri", 1024, "retention interval: drift steps between the last A-C session and the test, for -interf")
+		flag.BoolVar(&ss.Temporal.On, "temporal", false, "if true, run the temporal order and judgment of recency tests after each test (see Temporal params)")
+		flag.StringVar(&tmplayer, "tmplayer", "ECout", "layer of the retrieved context for -temporal: ECout or CA1")
+		flag.BoolVar(&ss.Decode.On, "decode", false, "if true, decode session and item from each layer after each test (see Decode params)")
+		flag.StringVar(&decsrc, "decsrc", "Trn", "trials to decode for -decode: Trn (study) or Tst (AB test)")
+		flag.BoolVar(&ss.RepDrift.On, "repdrift", false, "if true, compute same- and different-item similarity between study sessions at the end of each run (see RepDrift params)")
+		flag.BoolVar(&ss.RDM.Export, "rdmexport", false, "if true, save the model RDM of each layer after each test (see RDM params for the format)")
+		flag.StringVar(&ss.RDM.Files, "rdm", "", "comma-separated empirical RDM files, one per region, to compare with each layer after each test (see RDM params)")
+		flag.BoolVar(&ss.BOLD.On, "bold", false, "if true, make a synthetic BOLD time series of each layer over each run (see BOLD params)")
+		flag.StringVar(&boldsig, "boldsig", "Act", "per-trial layer signal for the synthetic BOLD: Act or Ge")
+		flag.StringVar(&ss.Trace.Items, "trace", "", "comma-separated test trials to trace every cycle, by TrialName or item number, or all (see Trace params for the file format)")
+		flag.StringVar(&tracelay, "tracelay", "CA3,ECout", "comma-separated layers to trace")
+		flag.StringVar(&edpairs, "edpairs", "Q1-P,M-P", "comma-separated quarter pairs (of Q1, Q2, M, P) for the error differences of each layer")
+		flag.StringVar(&ss.WtSnap.Prjns, "wtsnap", "", "comma-separated projections (e.g., ECinToCA3) to snapshot and analyze weight changes for (see WtSnap params)")
+		flag.BoolVar(&ss.RIF.On, "rif", false, "if true, use the retrieval-induced forgetting paradigm: categorized cues and retrieval practice after study (see RIF params)")
+		flag.BoolVar(&ss.ACh.On, "ach", false, "if true, modulate EC loop, CA3 recurrents and lrate by a novelty-driven ACh state (see ACh params)")
+		flag.StringVar(&ss.Theta.File, "theta", "", "JSON file with a theta-phase schedule for AlphaCyc (see Theta params)")
+		flag.StringVar(&ss.Lesion.Spec, "lesion", "", "lesions as Name:Kind[:Frac[:When[:Sessions]]];... (see Lesion params)")
 		flag.Parse()
 	}
 	//ss.expnum = 126        //uncomment this to impose an expnum while debugging - ALWAYS COMMENT OUT WHEN SUBMITTING ON CLUSTER
@@ -454,6 +631,9 @@ func (ss *Sim) New() {
 		ss.drifttype = ss.drifttypes + ss.expnum - ss.nints*ss.drifttypes
 		ss.exptype = 0
 	}
+	if et := ss.Interf.ExpType(); et >= 0 {
+		ss.exptype = et
+	}
 
 	if ss.exptype == 0 {
 		ss.pfix = "fcurve/"
@@ -477,6 +657,18 @@ func (ss *Sim) New() {
 	ss.TstStatNms = []string{"Mem", "TrgOnWasOff", "TrgOffWasOn"}
 	ss.SimMatStats = []string{"Within", "Between"}
 	ss.Defaults()
+	if len(os.Args) > 1 { // command line values of defaulted params
+		ss.Filler.PerFiller = float32(fillrate)
+		ss.DirForget.Shift = dfshift
+		ss.Interf.Lag = lag
+		ss.Interf.RI = ri
+		ss.Temporal.Layer = tmplayer
+		ss.Decode.Source = decsrc
+		ss.BOLD.Signal = boldsig
+		ss.Trace.Layers = tracelay
+		ss.ED.Pairs = edpairs
+		ss.Update()
+	}
 }
 
 func (pp *PatParams) Defaults() {
@@ -503,12 +695,47 @@ func (hp *HipParams) Defaults() {
 
 	hp.MossyDel = 4     // 4 > 2 -- best is 4 del on 4 rel baseline
 	hp.MossyDelTest = 3 // for rel = 4: 3 > 2 > 0 > 4 -- 4 is very bad -- need a small amount..
+
+	hp.DGTurnover = 0 // neurogenesis off
+	hp.DGMature = 500
+	hp.DGYoungLr = 3
 }
 
 func (ss *Sim) Defaults() {
 	ss.Hip.Defaults()
 	ss.Pat.Defaults()
 	ss.ErrLrMod.Defaults() //JWA
+	ss.Replay.Defaults()
+	ss.Filler.Defaults()
+	ss.Decay.Defaults()
+	ss.Decay.On = ss.synap_decay == 1
+	ss.Decay.Rate = ss.decay_rate
+	ss.Cascade.Defaults()
+	ss.Lesion.Defaults()
+	ss.Lesion.Lesions = ss.LegacyLesions()
+	ss.Theta.Defaults()
+	ss.ACh.Defaults()
+	ss.PrjnLrMod.Defaults()
+	ss.PatSep.Defaults()
+	ss.MST.Defaults()
+	ss.AssocInf.Defaults()
+	ss.RIF.Defaults()
+	ss.DirForget.Defaults()
+	ss.DirForget.On = ss.DirForget.Cue != ""
+	ss.Interf.Defaults()
+	ss.Temporal.Defaults()
+	ss.Decode.Defaults()
+	ss.RepDrift.Defaults()
+	ss.RDM.Defaults()
+	ss.BOLD.Defaults()
+	ss.Latency.Defaults()
+	ss.Trace.Defaults()
+	ss.WtSnap.Defaults()
+	ss.ED.Defaults()
+	ss.PrjnLrMod.On = ss.PrjnLrMod.Spec != ""
+	if ss.Arch == "" {
+		ss.Arch = ArchVariants[0].Name
+	}
 	ss.Time.CycPerQtr = 25 // note: key param - 25 seems like it is actually fine?
 	ss.Update()
 }
@@ -516,6 +743,29 @@ func (ss *Sim) Defaults() {
 func (ss *Sim) Update() {
 	ss.Hip.Update()
 	ss.ErrLrMod.Update() //JWA
+	ss.Replay.Update()
+	ss.Filler.Update()
+	ss.Decay.Update()
+	ss.Cascade.Update()
+	ss.Lesion.Update()
+	ss.Theta.Update()
+	ss.ACh.Update()
+	ss.PrjnLrMod.Update()
+	ss.PatSep.Update()
+	ss.MST.Update()
+	ss.AssocInf.Update()
+	ss.RIF.Update()
+	ss.DirForget.Update()
+	ss.Interf.Update()
+	ss.Temporal.Update()
+	ss.Decode.Update()
+	ss.RepDrift.Update()
+	ss.RDM.Update()
+	ss.BOLD.Update()
+	ss.Latency.Update()
+	ss.Trace.Update()
+	ss.WtSnap.Update()
+	ss.ED.Update()
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -532,6 +782,24 @@ func (ss *Sim) Config() {
 	ss.ConfigTstTrlLog(ss.TstTrlLog)
 	ss.ConfigTstCycLog(ss.TstCycLog)
 	ss.ConfigRunLog(ss.RunLog)
+	ss.ConfigReplayLog(ss.ReplayLog)
+	ss.ConfigReplayCounts(ss.ReplayCounts)
+	ss.ConfigPatSepLog(ss.PatSepLog)
+	ss.ConfigMSTLog(ss.MSTLog)
+	ss.ConfigAssocInfLog(ss.AssocInfLog)
+	ss.ConfigRIFLog(ss.RIFLog)
+	ss.ConfigDirForgetLog(ss.DirForgetLog)
+	ss.ConfigInterfLog(ss.InterfLog)
+	ss.ConfigTemporalLog(ss.TemporalLog)
+	ss.ConfigTrlStore(ss.TrlStore)
+	ss.ConfigDecodeLog(ss.DecodeLog)
+	ss.ConfigRepDriftLog(ss.RepDriftLog)
+	ss.ConfigRDMLog(ss.RDMLog)
+	ss.ConfigBOLDLog(ss.BOLDLog)
+	ss.ConfigTraceLog(ss.TraceLog)
+	ss.ConfigWtPoolLog(ss.WtPoolLog)
+	ss.ConfigWtItemLog(ss.WtItemLog)
+	ss.ConfigWtSimLog(ss.WtSimLog)
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -622,30 +890,31 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	ppathCA3 := prjn.NewUnifRnd()
 	ppathCA3.PCon = hp.CA3PCon
 
-	pj = net.ConnectLayersPrjn(ecin, dg, ppathDG, emer.Forward, &hip.CHLPrjn{})
+	pj = net.ConnectLayersPrjn(ecin, dg, ppathDG, emer.Forward, ss.HippoCHLPrjn())
 	pj.SetClass("HippoCHL")
 
-	if true { // toggle for bcm vs. ppath
-		pj = net.ConnectLayersPrjn(ecin, ca3, ppathCA3, emer.Forward, &hip.EcCa1Prjn{})
-		pj.SetClass("PPath")
-		pj = net.ConnectLayersPrjn(ca3, ca3, full, emer.Lateral, &hip.EcCa1Prjn{})
-		pj.SetClass("PPath")
-	} else {
+	av := ss.ArchVar() // architecture variant selects the learning rule per pathway
+	switch av.PPath {
+	case "CHL":
 		// so far, this is sig worse, even with error-driven MinusQ1 case (which is better than off)
-		pj = net.ConnectLayersPrjn(ecin, ca3, ppathCA3, emer.Forward, &hip.CHLPrjn{})
+		pj = net.ConnectLayersPrjn(ecin, ca3, ppathCA3, emer.Forward, ss.HippoCHLPrjn())
 		pj.SetClass("HippoCHL") //JWA, from Alan, was "PPath"
-		pj = net.ConnectLayersPrjn(ca3, ca3, full, emer.Lateral, &hip.CHLPrjn{})
+		pj = net.ConnectLayersPrjn(ca3, ca3, full, emer.Lateral, ss.HippoCHLPrjn())
 		pj.SetClass("HippoCHL") //JWA, from Alan, was "PPath"
+	default:
+		pj = net.ConnectLayersPrjn(ecin, ca3, ppathCA3, emer.Forward, ss.PPathPrjn())
+		pj.SetClass("PPath")
+		pj = net.ConnectLayersPrjn(ca3, ca3, full, emer.Lateral, ss.PPathPrjn())
+		pj.SetClass("PPath")
 	}
 
-	// always use this for now:
-	if true {
-		pj = net.ConnectLayersPrjn(ca3, ca1, full, emer.Forward, &hip.CHLPrjn{})
-		pj.SetClass("HippoCHL")
-	} else {
+	switch av.CA3CA1 {
+	case "XCal":
 		// note: this requires lrate = 1.0 or maybe 1.2, doesn't work *nearly* as well
 		pj = net.ConnectLayers(ca3, ca1, full, emer.Forward) // default con
-		// pj.SetClass("HippoCHL")
+	default:
+		pj = net.ConnectLayersPrjn(ca3, ca1, full, emer.Forward, ss.HippoCHLPrjn())
+		pj.SetClass("HippoCHL")
 	}
 
 	// Mossy fibers
@@ -702,6 +971,111 @@ func (ss *Sim) Init() {
 	ss.UpdateView(true)
 }
 
+// PrjnByName returns the projection with given name (e.g., ECinToCA3), or nil if not found
+func (ss *Sim) PrjnByName(nm string) *leabra.Prjn {
+	for _, pj := range ss.Prjns() {
+		if pj.Name() == nm {
+			return pj
+		}
+	}
+	return nil
+}
+
+// Prjns returns all the projections in the network
+func (ss *Sim) Prjns() []*leabra.Prjn {
+	var pjs []*leabra.Prjn
+	for _, ly := range ss.Net.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			pjs = append(pjs, p.(leabra.LeabraPrjn).AsLeabra())
+		}
+	}
+	return pjs
+}
+
+// GapLen returns the drift time (in trials) of the gap before study session epc,
+// from ss.fillers, or of the retention interval before the final test if
+// epc >= MaxEpcs.  Returns 0 in the no-drift conditions.
+func (ss *Sim) GapLen(epc int) int {
+	if epc <= 0 {
+		return 0
+	}
+	if epc >= ss.MaxEpcs {
+		if ss.interval == 0 { // no RI drift
+			return 0
+		}
+		return ss.testlag
+	}
+	if ss.drifttype == 0 || epc-1 >= len(ss.fillers) {
+		return 0
+	}
+	return ss.fillers[epc-1]
+}
+
+// SessionCtxt returns the name of the context vocab of study session epc
+// (0-based) for context pool i (0-based)
+func SessionCtxt(epc, i int) string {
+	if epc == 0 {
+		return fmt.Sprintf("ctxt_%d", i+1)
+	}
+	return fmt.Sprintf("midctxt_%d_%d", epc+1, i+1)
+}
+
+// ChainSessionCtxts regenerates the contexts of study sessions epc0 on, for
+// context pool i, each drifting on from the end of the previous session over
+// the fillers gap, with given between- and within-session drift rates
+func (ss *Sim) ChainSessionCtxts(i, epc0 int, drv, drvL float32) {
+	npats := ss.Pat.ListSize
+	for epc := epc0; epc < ss.MaxEpcs && epc-1 < len(ss.fillers); epc++ {
+		gap := ss.fillers[epc-1]
+		patgen.AddVocabClone(ss.PoolVocab, "clone", SessionCtxt(epc-1, i))
+		patgen.AddVocabDrift(ss.PoolVocab, GapCtxt(epc-1, i), gap+1, drv, "clone", npats-1)
+		patgen.AddVocabDrift(ss.PoolVocab, SessionCtxt(epc, i), npats, drvL, GapCtxt(epc-1, i), gap-1)
+	}
+}
+
+// SessionPats makes study patterns pairing cue vocabs a1-a4 with target
+// vocabs b1-b4, in the context of study session epc
+func (ss *Sim) SessionPats(nm, a, b string, epc int) *etable.Table {
+	hp := &ss.Hip
+	var inp []string
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, a+strconv.Itoa(i))
+	}
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, b+strconv.Itoa(i))
+	}
+	for i := 0; i < ss.cvcn*2; i++ {
+		inp = append(inp, SessionCtxt(epc, i))
+	}
+	dt := &etable.Table{}
+	patgen.InitPats(dt, nm+"_", nm+" Pats", "Input", "ECout", ss.Pat.ListSize, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+	patgen.MixPats(dt, ss.PoolVocab, "ECout", inp)
+	return dt
+}
+
+// TestPats makes test patterns in dt, cued by vocabs a1-a4 with vocabs b1-b4
+// as the targets, in the test context
+func (ss *Sim) TestPats(dt *etable.Table, nm, a, b string) {
+	hp := &ss.Hip
+	var inp, out []string
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, a+strconv.Itoa(i))
+	}
+	out = append(out, inp...)
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, "empty")
+		out = append(out, b+strconv.Itoa(i))
+	}
+	for i := 0; i < ss.cvcn*2; i++ {
+		inp = append(inp, fmt.Sprintf("ctxtT_%d", i+1))
+		out = append(out, fmt.Sprintf("ctxtT_%d", i+1))
+	}
+	patgen.InitPats(dt, nm+"_", nm+" Pats", "Input", "ECout", ss.Pat.ListSize, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+	patgen.MixPats(dt, ss.PoolVocab, "ECout", out)
+}
+
 // NewRndSeed gets a new random seed based on current time -- otherwise uses
 // the same random seed for every run
 func (ss *Sim) NewRndSeed() {
@@ -750,47 +1124,15 @@ func (ss *Sim) AlphaCyc(train bool) {
 		ss.Net.WtFmDWt()
 	}*/ //4/29/22 copied down below to fix DWt rounding issue
 
-	ca1 := ss.Net.LayerByName("CA1").(leabra.LeabraLayer).AsLeabra()
-	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
-	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
-	ecin := ss.Net.LayerByName("ECin").(leabra.LeabraLayer).AsLeabra()
 	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
-	dg := ss.Net.LayerByName("DG").(leabra.LeabraLayer).AsLeabra() //JWA
-	ca1FmECin := ca1.RcvPrjns.SendName("ECin").(leabra.LeabraPrjn).AsLeabra()
-	ca1FmCa3 := ca1.RcvPrjns.SendName("CA3").(leabra.LeabraPrjn).AsLeabra()
-	ca3FmDg := ca3.RcvPrjns.SendName("DG").(leabra.LeabraPrjn).AsLeabra()
-	ca3FmCa3 := ca3.RcvPrjns.SendName("CA3").(leabra.LeabraPrjn).AsLeabra()
-	ca3FmECin := ca3.RcvPrjns.SendName("ECin").(leabra.LeabraPrjn).AsLeabra() //JWA add
-	DgFmECin := dg.RcvPrjns.SendName("ECin").(leabra.LeabraPrjn).AsLeabra()
-	_ = ecin
-	_ = input
-	if ss.ECtoDGnl == 1 {
-		DgFmECin.Learn.Learn = false
-	}
-	if ss.ECtoCA3nl == 1 {
-		ca3FmECin.Learn.Learn = false
-	}
-	if ss.ECtoCA1nl == 1 {
-		ca1FmECin.Learn.Learn = false
-	}
-	if ss.CA3toCA1nl == 1 {
-		ca1FmCa3.Learn.Learn = false
-	}
-	if ss.CA3toCA3nl == 1 {
-		ca3FmCa3.Learn.Learn = false
-	}
+	unlesion := ss.ApplyLesions(train) // replaces the ECtoDGnl etc switches
 
-	// First Quarter: CA1 is driven by ECin, not by CA3 recall
-	// (which is not really active yet anyway)
-	ca1FmECin.WtScale.Abs = 1
-	ca1FmCa3.WtScale.Abs = 0
-
-	dgwtscale := ca3FmDg.WtScale.Rel
-	// 0 for the first quarter, comment out if testing in orig, zycyc
-	//JWA, comment out to turn off error-driven learning
-	if ss.edl == 1 {
-		ca3FmDg.WtScale.Rel = dgwtscale - ss.Hip.MossyDel
-	}
+	// theta schedule: by default, Q1 CA1 driven by ECin, Q2-Q3 by CA3 recall,
+	// Q4 by ECin with ECout clamped (see StdTheta)
+	sched := ss.ThetaSchedule()
+	unscale := ss.SaveThetaScales(sched)
+	ss.ApplyThetaScales(&sched[0], train)
+	unach := ss.AChStart()
 
 	if train {
 		ecout.SetType(emer.Target) // clamp a plus phase during testing
@@ -801,17 +1143,24 @@ func (ss *Sim) AlphaCyc(train bool) {
 
 	ss.Net.AlphaCycInit() //JWA: triggers decay to run in most/all layers (e.g. CA3)
 	ss.Time.AlphaCycStart()
-	for qtr := 0; qtr < 4; qtr++ {
-		for cyc := 0; cyc < ss.Time.CycPerQtr; cyc++ {
+	for pi := range sched {
+		ph := &sched[pi]
+		ncyc := ph.Cycles
+		if ncyc <= 0 {
+			ncyc = ss.Time.CycPerQtr
+		}
+		for cyc := 0; cyc < ncyc; cyc++ {
 			ss.Net.Cycle(&ss.Time)
 			if !train {
 				ss.LogTstCyc(ss.TstCycLog, ss.Time.Cycle)
+				ss.LatencyCyc(ss.Time.Cycle)
+				ss.TraceCyc(ss.Time.Cycle)
 			}
 			ss.Time.CycleInc()
 			if ss.ViewOn {
 				switch viewUpdt {
 				case leabra.Cycle:
-					if cyc != ss.Time.CycPerQtr-1 { // will be updated by quarter
+					if cyc != ncyc-1 { // will be updated by quarter
 						ss.UpdateView(train)
 					}
 				case leabra.FastSpike:
@@ -821,31 +1170,31 @@ func (ss *Sim) AlphaCyc(train bool) {
 				}
 			}
 		}
-		switch qtr + 1 {
-		case 1: // Second, Third Quarters: CA1 is driven by CA3 recall
-			ca1FmECin.WtScale.Abs = 0
-			ca1FmCa3.WtScale.Abs = 1
-
-			//ca3FmDg.WtScale.Rel = dgwtscale //JWA
-			if train {
-				ca3FmDg.WtScale.Rel = dgwtscale
-			} else {
-				ca3FmDg.WtScale.Rel = dgwtscale - ss.Hip.MossyDelTest // testing
+		if pi+1 < len(sched) { // set up the next phase
+			nph := &sched[pi+1]
+			rescale := ss.AChPhase(pi)
+			if len(nph.Scales) > 0 {
+				ss.ApplyThetaScales(nph, train)
+				rescale = true
 			}
-			ss.Net.GScaleFmAvgAct() // update computed scaling factors
-			ss.Net.InitGInc()       // scaling params change, so need to recompute all netins
-		case 3: // Fourth Quarter: CA1 back to ECin drive only
-			ca1FmECin.WtScale.Abs = 1
-			ca1FmCa3.WtScale.Abs = 0
-			ss.Net.GScaleFmAvgAct() // update computed scaling factors
-			ss.Net.InitGInc()       // scaling params change, so need to recompute all netins
-			if train {              // clamp ECout from ECin
-				ecin.UnitVals(&ss.TmpVals, "Act") // note: could use input instead -- not much diff
-				ecout.ApplyExt1D32(ss.TmpVals)
+			if rescale {
+				ss.Net.GScaleFmAvgAct() // update computed scaling factors
+				ss.Net.InitGInc()       // scaling params change, so need to recompute all netins
 			}
+			if train && nph.Clamp != "" {
+				if ss.ClampLay != "" {
+					ss.ThetaClamp(ss.ClampLay)
+				} else {
+					ss.ThetaClamp(nph.Clamp)
+				}
+			}
+		}
+		if !ph.QtrEnd {
+			continue
 		}
+		qtr := ss.Time.Quarter
 		ss.Net.QuarterFinal(&ss.Time)
-		if qtr+1 == 3 {
+		if ph.MemStats {
 			ss.MemStats(train) // must come after QuarterFinal
 		}
 		ss.Time.QuarterInc()
@@ -861,22 +1210,31 @@ func (ss *Sim) AlphaCyc(train bool) {
 		}
 	}
 
-	ca3FmDg.WtScale.Rel = dgwtscale // restore
-	ca1FmCa3.WtScale.Abs = 1
+	unach()
+	unscale()            // restore
 	ss.TrialStats(train) //JWA, moved from TrainTrial: logic here, this gives you error signal from this trial
 
 	if train {
 		//JWA, for "neuromodulation"
 		//ss.ErrLrMod.LrateMod(float32(1 - ss.TrlCosDiff)) // previous
+		lrm := ss.ErrLrMod.LrateMod(float32(ss.TrlEDCA3)) //JWA
 		if ss.lratemulton == 0 {
-			ss.ErrLrMod.LrateMod(float32(ss.TrlEDCA3))
-		} else {
-			lrm := ss.ErrLrMod.LrateMod(float32(ss.TrlEDCA3)) //JWA
-			ss.Net.LrateMult(lrm)                             //JWA, this multiplies lrate based on lrm
+			lrm = 1
+		}
+		if ss.ACh.On {
+			lrm *= ss.ACh.LrateMult(ss.TrlACh)
+		}
+		if ss.lratemulton != 0 || ss.ACh.On || ss.PrjnLrMod.On {
+			ss.Net.LrateMult(lrm) //JWA, this multiplies lrate based on lrm
+		}
+		if ss.PrjnLrMod.On {
+			ss.PrjnLrateMult()
 		}
 		ss.Net.DWt()
+		ss.NeurogenDWt()
 		ss.Net.WtFmDWt() //4/29/22 fixed, added from above in AlphaCyc, not in original hip_bench here
 	}
+	unlesion() // after DWt, so NoLearn lesions hold
 	if ss.ViewOn && viewUpdt == leabra.AlphaCycle {
 		ss.UpdateView(train)
 	}
@@ -887,6 +1245,58 @@ func (ss *Sim) AlphaCyc(train bool) {
 	}
 }
 
+// SettleCyc runs one alpha-cycle of free settling through the theta schedule,
+// with ECout unclamped, but without the cycle log, latency, trace, ACh and
+// MemStats / TrialStats -- for offline trials (replay, probes) that are not
+// tests.  The theta scales and lesions of the train (or test) phase apply,
+// and if learn is true, DWt and WtFmDWt are called before they are lifted.
+// External inputs must have already been applied prior to calling.
+func (ss *Sim) SettleCyc(train, learn bool) {
+	unlesion := ss.ApplyLesions(train)
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	sched := ss.ThetaSchedule()
+	unscale := ss.SaveThetaScales(sched)
+	ss.ApplyThetaScales(&sched[0], train)
+
+	ecout.SetType(emer.Compare) // don't clamp
+	ecout.UpdateExtFlags()
+
+	ss.Net.AlphaCycInit()
+	ss.Time.AlphaCycStart()
+	for pi := range sched {
+		ph := &sched[pi]
+		ncyc := ph.Cycles
+		if ncyc <= 0 {
+			ncyc = ss.Time.CycPerQtr
+		}
+		for cyc := 0; cyc < ncyc; cyc++ {
+			ss.Net.Cycle(&ss.Time)
+			ss.Time.CycleInc()
+		}
+		if pi+1 < len(sched) { // set up the next phase
+			nph := &sched[pi+1]
+			if len(nph.Scales) > 0 {
+				ss.ApplyThetaScales(nph, train)
+				ss.Net.GScaleFmAvgAct() // update computed scaling factors
+				ss.Net.InitGInc()       // scaling params change, so need to recompute all netins
+			}
+		}
+		if ph.QtrEnd {
+			ss.Net.QuarterFinal(&ss.Time)
+			ss.Time.QuarterInc()
+		}
+	}
+	unscale() // restore
+	if learn {
+		ss.Net.DWt()
+		ss.Net.WtFmDWt()
+	}
+	unlesion()
+	if ss.ViewOn {
+		ss.UpdateView(train)
+	}
+}
+
 // ApplyInputs applies input patterns from given environment.
 // It is good practice to have this be a separate method with appropriate
 // args so that it can be used for various different contexts
@@ -927,8 +1337,17 @@ func (ss *Sim) TrainTrial() {
 	// Key to query counters FIRST because current state is in NEXT epoch
 	// if epoch counter has changed
 	epc, _, chg := ss.TrainEnv.Counter(env.Epoch)
+	if epc == 0 && ss.TrainEnv.Trial.Cur == 0 {
+		ss.WtSnapshot("Init", 0) // before the first study session
+	}
 	if chg {
 		ss.LogTrnEpc(ss.TrnEpcLog)
+		ss.WtSnapshot("Study", epc-1)
+		ss.RIFPractice(epc) // retrieval practice at the end of study, before the retention interval
+		ss.ReplayPhase(epc) // offline phase in the gap before the next session (or test)
+		ss.FillerPhase(epc)
+		ss.DecayPhase(epc)
+		ss.WtSnapshot("Offline", epc-1)
 		if ss.ViewOn && ss.TrainUpdt > leabra.AlphaCycle {
 			ss.UpdateView(true)
 		}
@@ -981,6 +1400,15 @@ func (ss *Sim) TrainTrial() {
 				}
 			}
 		}
+		if ss.AssocInf.On {
+			ss.AssocInfSession(epc)
+		}
+		if ss.DirForget.On {
+			ss.DirForgetSession(epc)
+		}
+		if ss.exptype >= 2 {
+			ss.InterfSession(epc)
+		}
 		if epc >= ss.MaxEpcs || ss.MaxEpcs == 0 { // done with training. //JWA added || part?
 			ss.RunEnd()
 			if ss.TrainEnv.Run.Incr() { // we are done!
@@ -1075,6 +1503,12 @@ func (ss *Sim) PreTrainTrial() {
 // RunEnd is called at the end of a run -- save weights, record final log, etc here
 func (ss *Sim) RunEnd() {
 	ss.LogRun(ss.RunLog)
+	if ss.RepDrift.On {
+		ss.RepDriftRun()
+	}
+	if ss.BOLD.On {
+		ss.BOLDRun()
+	}
 	if ss.SaveWts {
 		fnm := ss.WeightsFileName()
 		fmt.Printf("Saving Weights to: %v\n", fnm)
@@ -1094,10 +1528,17 @@ func (ss *Sim) NewRun() {
 	ss.Net.LrateMult(1) //JWA add, via Randy's zulip comment
 	ss.Net.InitWts()
 	ss.LoadPretrainedWts()
+	ss.InitElapsePrjns() // cascade state must match any loaded weights
+	ss.InitDGAge()
 	ss.InitStats()
 	ss.TrnTrlLog.SetNumRows(0)
 	ss.TrnEpcLog.SetNumRows(0)
 	ss.TstEpcLog.SetNumRows(0)
+	ss.TrlStore.SetNumRows(0)
+	ss.BOLDEvents = nil
+	ss.BOLDTstN = 0
+	ss.WtSnapPrv = nil
+	ss.WtFPs = nil
 	ss.NeedsNewRun = false
 }
 
@@ -1126,6 +1567,8 @@ func (ss *Sim) InitStats() {
 	ss.CntErr = 0
 	ss.FirstZero = -1
 	ss.NZero = 0
+	ss.TrlACh = 0
+	ss.EDSums = map[string]float64{}
 	// clear rest just to make Sim look initialized
 	ss.Mem = 0
 	ss.TrgOnWasOffAll = 0
@@ -1139,6 +1582,19 @@ func (ss *Sim) InitStats() {
 	ss.EpcCosDiff = 0
 }
 
+// MemRange returns the range of ECout units scored by MemStats: the target,
+// or the temporal context if targortemp == 2
+func (ss *Sim) MemRange() (ind1, ind2 int) {
+	hp := &ss.Hip
+	ind1 = ss.wpvc * hp.ECPool.Y * hp.ECPool.X     //start after cue
+	ind2 = ss.wpvc * 2 * hp.ECPool.Y * hp.ECPool.X //go until end of target (assumes cue and target sizes are equal)
+	if ss.targortemp == 2 {                        // test temporal context instead
+		ind1 = ss.wpvc * 2 * hp.ECPool.Y * hp.ECPool.X
+		ind2 = (ss.wpvc + ss.cvcn) * 2 * hp.ECPool.Y * hp.ECPool.X
+	}
+	return
+}
+
 // MemStats computes ActM vs. Target on ECout with binary counts
 // must be called at end of 3rd quarter so that Targ values are
 // for the entire full pattern as opposed to the plus-phase target
@@ -1156,13 +1612,7 @@ func (ss *Sim) MemStats(train bool) {
 	actMi, _ := ecout.UnitVarIdx("ActM")
 	targi, _ := ecout.UnitVarIdx("Targ")
 	actQ1i, _ := ecout.UnitVarIdx("ActQ1")
-	hp := &ss.Hip
-	ind1 := ss.wpvc * hp.ECPool.Y * hp.ECPool.X     //start after cue
-	ind2 := ss.wpvc * 2 * hp.ECPool.Y * hp.ECPool.X //go until end of target (assumes cue and target sizes are equal)
-	if ss.targortemp == 2 {                         // test temporal context instead
-		ind1 = ss.wpvc * 2 * hp.ECPool.Y * hp.ECPool.X
-		ind2 = (ss.wpvc + ss.cvcn) * 2 * hp.ECPool.Y * hp.ECPool.X
-	}
+	ind1, ind2 := ss.MemRange()
 	//fmt.Printf("\n")
 	for ni := ind1; ni < ind2; ni++ {
 		//for ni := 0; ni < nn; ni++ { //JWA this was old code that started from 0
@@ -1222,6 +1672,16 @@ func (ss *Sim) MemStats(train bool) {
 	//fmt.Printf("trgOffWasOn: %v\n", trgOffWasOn)
 }
 
+// SaveTrlSums records the epoch accumulators of TrialStats, and returns a
+// function that restores them -- for trials that are not part of the epoch
+// (e.g., retrieval practice and fillers between study sessions)
+func (ss *Sim) SaveTrlSums() func() {
+	sse, avgsse, cosdiff, cnterr := ss.SumSSE, ss.SumAvgSSE, ss.SumCosDiff, ss.CntErr
+	return func() {
+		ss.SumSSE, ss.SumAvgSSE, ss.SumCosDiff, ss.CntErr = sse, avgsse, cosdiff, cnterr
+	}
+}
+
 // TrialStats computes the trial-level statistics and adds them to the epoch accumulators if
 // accum is true.  Note that we're accumulating stats here on the Sim side so the
 // core algorithm side remains as simple as possible, and doesn't need to worry about
@@ -1252,6 +1712,15 @@ func (ss *Sim) TrialStats(accum bool) (sse, avgsse, cosdiff float64) { //JWA do
 	ly.UnitValsTensor(tsrq4, "ActP")
 	actavgg := ly.Pools[0].Inhib.Act.Avg //average for this single trial                     //JWA, effective, most stable
 	ss.TrlEDCA3 = metric.Abs32(tsrq1.Values, tsrq4.Values) / (actavgg * float32(tsrq4.Len()))
+
+	// same for ECout minus vs. plus phase
+	tsrq3 := ss.ValsTsr("ECoutActM")
+	tsrq4 = ss.ValsTsr("ECoutActP")
+	outLay.UnitValsTensor(tsrq3, "ActM")
+	outLay.UnitValsTensor(tsrq4, "ActP")
+	actavgg = outLay.Pools[0].Inhib.Act.Avg
+	ss.TrlEDECout = metric.Abs32(tsrq3.Values, tsrq4.Values) / (actavgg * float32(tsrq4.Len()))
+	ss.EDStats()
 	return
 }
 
@@ -1326,6 +1795,7 @@ func (ss *Sim) SetDgCa3Off(net *leabra.Network, off bool) {
 
 // PreTrain runs pre-training, saves weights to PreTrainWts
 func (ss *Sim) PreTrain() {
+	ss.PreTraining = true
 	ss.SetDgCa3Off(ss.Net, true)
 	ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAll)
 	ss.TrainEnv.Init(ss.TrainEnv.Run.Cur) //JWA, from XL!
@@ -1344,6 +1814,7 @@ func (ss *Sim) PreTrain() {
 	ss.TrainEnv.Table = etable.NewIdxView(ss.TrainAB)
 	ss.TrainEnv.Init(ss.TrainEnv.Run.Cur) //JWA, from XL!
 	ss.SetDgCa3Off(ss.Net, false)
+	ss.PreTraining = false
 	ss.Stopped()
 }
 
@@ -1431,6 +1902,27 @@ func (ss *Sim) TestItem(idx int) {
 	ss.TestEnv.Trial.Cur = cur
 }
 
+// TestTable runs through all the items in given table without learning or
+// logging, calling fun after the stats are computed for each trial, and
+// then sets the TestEnv back to the TestAB patterns
+func (ss *Sim) TestTable(dt *etable.Table, fun func()) {
+	ss.TestEnv.Table = etable.NewIdxView(dt)
+	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	for {
+		ss.TestEnv.Step()
+		_, _, chg := ss.TestEnv.Counter(env.Epoch)
+		if chg || ss.StopNow {
+			break
+		}
+		ss.Net.InitActs()
+		ss.ApplyInputs(&ss.TestEnv)
+		ss.AlphaCyc(false)   // !train
+		ss.TrialStats(false) // !accumulate
+		fun()
+	}
+	ss.TestEnv.Table = etable.NewIdxView(ss.TestAB)
+}
+
 // TestAll runs through the full set of testing items
 func (ss *Sim) TestAll() {
 	ss.TestNm = "AB"
@@ -1446,6 +1938,31 @@ func (ss *Sim) TestAll() {
 
 	// log only at very end
 	ss.LogTstEpc(ss.TstEpcLog)
+	if ss.RDM.On() {
+		ss.RDMTest()
+	}
+
+	if ss.MST.On {
+		ss.MSTTest()
+	}
+	if ss.AssocInf.On {
+		ss.AssocInfTest()
+	}
+	if ss.RIF.On {
+		ss.RIFTest()
+	}
+	if ss.DirForget.On {
+		ss.DirForgetTest()
+	}
+	if ss.exptype >= 2 {
+		ss.InterfTest()
+	}
+	if ss.Temporal.On {
+		ss.TemporalTest()
+	}
+	if ss.Decode.On {
+		ss.DecodeTest()
+	}
 }
 
 // RunTestAll runs through the full set of testing items, has stop running = false at end -- for gui
@@ -1466,16 +1983,20 @@ func (ss *Sim) ParamsName() string {
 	return ss.ParamSet
 }
 
-// SetParams sets the params for "Base" and then current ParamSet.
+// SetParams sets the params for "Base", then the CA3CA1_<rule> and Arch_<name>
+// sets for the architecture variant (if any), and then current ParamSet.
 // If sheet is empty, then it applies all avail sheets (e.g., Network, Sim)
 // otherwise just the named sheet
 // if setMsg = true then we output a message for each param that was set.
 func (ss *Sim) SetParams(sheet string, setMsg bool) error {
 	if sheet == "" {
 		// this is important for catching typos and ensuring that all sheets can be used
-		ss.Params.ValidateSheets([]string{"Network", "Sim", "Hip", "Pat"})
+		ss.Params.ValidateSheets([]string{"Network", "Sim", "Hip", "Pat", "Replay", "Filler", "Decay", "Cascade", "Lesion", "Theta", "ACh", "PrjnLrMod", "PatSep", "MST", "AssocInf", "RIF", "DirForget", "Interf", "Temporal", "Decode", "RepDrift", "RDM", "BOLD", "Latency", "Trace", "WtSnap", "ED"})
 	}
 	err := ss.SetParamsSet("Base", sheet, setMsg)
+	for _, anm := range ss.ArchParamSets() {
+		err = ss.SetParamsSet(anm, sheet, setMsg)
+	}
 	if ss.ParamSet != "" && ss.ParamSet != "Base" {
 		err = ss.SetParamsSet(ss.ParamSet, sheet, setMsg)
 	}
@@ -1527,6 +2048,168 @@ func (ss *Sim) SetParamsSet(setNm string, sheet string, setMsg bool) error {
 		}
 	}
 
+	if sheet == "" || sheet == "Replay" {
+		simp, ok := pset.Sheets["Replay"]
+		if ok {
+			simp.Apply(&ss.Replay, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Filler" {
+		simp, ok := pset.Sheets["Filler"]
+		if ok {
+			simp.Apply(&ss.Filler, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Decay" {
+		simp, ok := pset.Sheets["Decay"]
+		if ok {
+			simp.Apply(&ss.Decay, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Cascade" {
+		simp, ok := pset.Sheets["Cascade"]
+		if ok {
+			simp.Apply(&ss.Cascade, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Lesion" {
+		simp, ok := pset.Sheets["Lesion"]
+		if ok {
+			simp.Apply(&ss.Lesion, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Theta" {
+		simp, ok := pset.Sheets["Theta"]
+		if ok {
+			simp.Apply(&ss.Theta, setMsg)
+			ss.Theta.Update() // load any File
+		}
+	}
+
+	if sheet == "" || sheet == "ACh" {
+		simp, ok := pset.Sheets["ACh"]
+		if ok {
+			simp.Apply(&ss.ACh, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "PrjnLrMod" {
+		simp, ok := pset.Sheets["PrjnLrMod"]
+		if ok {
+			simp.Apply(&ss.PrjnLrMod, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "PatSep" {
+		simp, ok := pset.Sheets["PatSep"]
+		if ok {
+			simp.Apply(&ss.PatSep, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "MST" {
+		simp, ok := pset.Sheets["MST"]
+		if ok {
+			simp.Apply(&ss.MST, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "AssocInf" {
+		simp, ok := pset.Sheets["AssocInf"]
+		if ok {
+			simp.Apply(&ss.AssocInf, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "RIF" {
+		simp, ok := pset.Sheets["RIF"]
+		if ok {
+			simp.Apply(&ss.RIF, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "DirForget" {
+		simp, ok := pset.Sheets["DirForget"]
+		if ok {
+			simp.Apply(&ss.DirForget, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Interf" {
+		simp, ok := pset.Sheets["Interf"]
+		if ok {
+			simp.Apply(&ss.Interf, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Temporal" {
+		simp, ok := pset.Sheets["Temporal"]
+		if ok {
+			simp.Apply(&ss.Temporal, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Decode" {
+		simp, ok := pset.Sheets["Decode"]
+		if ok {
+			simp.Apply(&ss.Decode, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "RepDrift" {
+		simp, ok := pset.Sheets["RepDrift"]
+		if ok {
+			simp.Apply(&ss.RepDrift, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "RDM" {
+		simp, ok := pset.Sheets["RDM"]
+		if ok {
+			simp.Apply(&ss.RDM, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "BOLD" {
+		simp, ok := pset.Sheets["BOLD"]
+		if ok {
+			simp.Apply(&ss.BOLD, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Latency" {
+		simp, ok := pset.Sheets["Latency"]
+		if ok {
+			simp.Apply(&ss.Latency, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "Trace" {
+		simp, ok := pset.Sheets["Trace"]
+		if ok {
+			simp.Apply(&ss.Trace, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "WtSnap" {
+		simp, ok := pset.Sheets["WtSnap"]
+		if ok {
+			simp.Apply(&ss.WtSnap, setMsg)
+		}
+	}
+
+	if sheet == "" || sheet == "ED" {
+		simp, ok := pset.Sheets["ED"]
+		if ok {
+			simp.Apply(&ss.ED, setMsg)
+		}
+	}
+
 	// note: if you have more complex environments with parameters, definitely add
 	// sheets for them, e.g., "TrainEnv", "TestEnv" etc
 	return err
@@ -1576,6 +2259,9 @@ func (ss *Sim) ConfigPats() {
 	patgen.AddVocabPermutedBinary(ss.PoolVocab, "A2", npats, plY, plX, pctAct, minDiff)
 	patgen.AddVocabPermutedBinary(ss.PoolVocab, "A3", npats, plY, plX, pctAct, minDiff)
 	patgen.AddVocabPermutedBinary(ss.PoolVocab, "A4", npats, plY, plX, pctAct, minDiff)
+	if ss.RIF.On { // categories share cue pools
+		ss.RIFCats(npats, pctAct, minDiff)
+	}
 	//NOTE "A1-4" will be altered in the RIn condition below
 	patgen.AddVocabPermutedBinary(ss.PoolVocab, "B1", npats, plY, plX, pctAct, minDiff) //targets
 	patgen.AddVocabPermutedBinary(ss.PoolVocab, "B2", npats, plY, plX, pctAct, minDiff)
@@ -2115,8 +2801,6 @@ func (ss *Sim) ConfigPats() {
 	halftime := int(math.Pow(2, float64(8)))       //relevant for exptype>0
 	endtimef := 10                                 //10
 	endtime := int(math.Pow(2, float64(endtimef))) //relevant for exptype>0
-	fir := npats * 3                               //fir and las are for timing the PI/RI experiments
-	las := halftime - npats*3
 	fmt.Printf("exptype: %d\n", exptype)
 	fmt.Printf("interval: %d\n", interval)
 	fmt.Printf("npats: %d\n", npats)
@@ -2124,23 +2808,13 @@ func (ss *Sim) ConfigPats() {
 	if exptype == 0 { //fcurve
 		preablag = halftime
 		testlag = int(math.Pow(2, float64(endtimef-powoff+interval-1))) //-1 added when we made interval=0 the final learning temporal context
-	} else if exptype < 3 { //PI/sp
+	} else if exptype == 1 { //sp
 		preablag = npats + halftime - int(math.Pow(2, float64((nints-1)-interval))) //JWA, fixed, 8/5/21 was 4+(4-interval), as above
 		abaclag = halftime + npats - preablag
 		testlag = endtime
-	} else { //RI or RIn - all outdated!!!
-		if interval == 0 {
-			abaclag = fir
-		} else if interval == 1 {
-			abaclag = fir + (las-fir)/4
-		} else if interval == 2 {
-			abaclag = fir + (las-fir)/2
-		} else if interval == 3 {
-			abaclag = fir + (las-fir)*3/4
-		} else if interval == 4 {
-			abaclag = las
-		}
-		testlag = endtime - (abaclag)
+	} else { //PI, RI or RIn: explicit lag between the lists and RI before test (see Interf params)
+		abaclag = ss.Interf.Lag
+		testlag = ss.Interf.RI
 	}
 	ss.testlag = testlag + 0
 	fmt.Printf("pre ab lag: %d\n", preablag)
@@ -2274,11 +2948,11 @@ func (ss *Sim) ConfigPats() {
 		patgen.AddVocabDrift(ss.PoolVocab, ctxtNm1, npats, drvL, "clone", preablag-1) //add drift during first learned list
 
 		//pre-allocate; allows drift between epochs!
-		fill1 := fmt.Sprintf("fill1")
-		fill2 := fmt.Sprintf("fill2")
-		fill3 := fmt.Sprintf("fill3")
-		fill4 := fmt.Sprintf("fill4")
-		fill5 := fmt.Sprintf("fill5")
+		fill1 := GapCtxt(0, i)
+		fill2 := GapCtxt(1, i)
+		fill3 := GapCtxt(2, i)
+		fill4 := GapCtxt(3, i)
+		fill5 := GapCtxt(4, i)
 		ctxtNm_1_2 := fmt.Sprintf("midctxt_2_%d", i+1) //various context names for later training epochs
 		ctxtNm_1_3 := fmt.Sprintf("midctxt_3_%d", i+1)
 		ctxtNm_1_4 := fmt.Sprintf("midctxt_4_%d", i+1)
@@ -2374,6 +3048,15 @@ func (ss *Sim) ConfigPats() {
 				patgen.AddVocabDrift(ss.PoolVocab, ctxtNm_1_6, npats, drvL, "clone", filler-1)
 			}
 		}
+		if ss.AssocInf.On { // B-C sessions follow the last A-B session after AssocInf.Lag
+			ss.AssocInfCtxts(i, drv, drvL)
+		}
+		if ss.DirForget.On { // a Forget cue between the lists shifts the context
+			ss.DirForgetCtxts(i, drv, drvL)
+		}
+		if exptype >= 2 { // AC sessions follow the last AB session after abaclag
+			ss.InterfCtxts(i, drv, drvL)
+		}
 		fmt.Printf("fillers epc: %v\n", ss.fillers)
 
 		////// MUST touch this up if we run other exptypes!!
@@ -2391,13 +3074,14 @@ func (ss *Sim) ConfigPats() {
 		} else if ss.driftbetween == 0 {
 			lastctxt = ctxtNm1
 		}
+		if exptype >= 2 {
+			lastctxt = SessionCtxt(ss.MaxEpcs-1, i)
+		}
 		//fmt.Printf("lastctxt: %v\n", lastctxt)
 		ctxtNm4 := fmt.Sprintf("lagbeforetest_%d", i+1)
 		ctxtNm5 := fmt.Sprintf("ctxtT_%d", i+1) //test context
-		if exptype <= 1 {                       // no AC, use after last epoch!
-			patgen.AddVocabClone(ss.PoolVocab, "clone", lastctxt)
-			patgen.AddVocabDrift(ss.PoolVocab, ctxtNm4, testlag, drv, "clone", npats-1) // drift after AB, import from end of AB list
-		}
+		patgen.AddVocabClone(ss.PoolVocab, "clone", lastctxt)
+		patgen.AddVocabDrift(ss.PoolVocab, ctxtNm4, testlag, drv, "clone", npats-1) // drift after the last list, import from its end
 		patgen.AddVocabClone(ss.PoolVocab, "clone", ctxtNm4)
 		patgen.AddVocabDrift(ss.PoolVocab, ctxtNm5, npats, drvL, "clone", testlag-1) // drift within test, import from end of AC-test interval
 
@@ -2684,8 +3368,8 @@ func (ss *Sim) ConfigPats() {
 
 	if exptype != 1 {
 		patgen.InitPats(ss.TrainAC, "TrainAC_", "TrainAC Pats", "Input", "ECout", npats, ecY, ecX, plY, plX)
-		patgen.MixPats(ss.TrainAC, ss.PoolVocab, "Input", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8", "ctxt_AC9", "ctxt_AC10", "ctxt_AC11", "ctxt_AC12"})
-		patgen.MixPats(ss.TrainAC, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8", "ctxt_AC9", "ctxt_AC10", "ctxt_AC11", "ctxt_AC12"})
+		patgen.MixPats(ss.TrainAC, ss.PoolVocab, "Input", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8"})
+		patgen.MixPats(ss.TrainAC, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8"})
 		patgen.InitPats(ss.TestAC, "TestAC_", "TestAC Pats", "Input", "ECout", npats, ecY, ecX, plY, plX)
 
 		if blankouttc == 0 {
@@ -2705,14 +3389,14 @@ func (ss *Sim) ConfigPats() {
 		if ss.targortemp == 1 {
 			patgen.MixPats(ss.TestAC, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxtT_1", "ctxtT_2", "ctxtT_3", "ctxtT_4", "ctxtT_5", "ctxtT_6", "ctxtT_7", "ctxtT_8"})
 		} else if ss.targortemp == 2 {
-			patgen.MixPats(ss.TestAC, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8", "ctxt_AC9", "ctxt_AC10", "ctxt_AC11", "ctxt_AC12"})
+			patgen.MixPats(ss.TestAC, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8"})
 		}
 		patgen.InitPats(ss.TestACnc, "TestACnc_", "TestAC Pats, no temp context", "Input", "ECout", npats, ecY, ecX, plY, plX)
 		patgen.MixPats(ss.TestACnc, ss.PoolVocab, "Input", []string{"A1", "A2", "A3", "A4", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty", "empty"})
 		if ss.targortemp == 1 {
 			patgen.MixPats(ss.TestACnc, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxtT_1", "ctxtT_2", "ctxtT_3", "ctxtT_4", "ctxtT_5", "ctxtT_6", "ctxtT_7", "ctxtT_8"})
 		} else if ss.targortemp == 2 {
-			patgen.MixPats(ss.TestACnc, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8", "ctxt_AC9", "ctxt_AC10", "ctxt_AC11", "ctxt_AC12"})
+			patgen.MixPats(ss.TestACnc, ss.PoolVocab, "ECout", []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4", "ctxt_AC1", "ctxt_AC2", "ctxt_AC3", "ctxt_AC4", "ctxt_AC5", "ctxt_AC6", "ctxt_AC7", "ctxt_AC8"})
 		}
 	} else { //sp
 		patgen.InitPats(ss.TrainAC, "TrainAC_", "TrainAC Pats", "Input", "ECout", npats, ecY, ecX, plY, plX)
@@ -2760,6 +3444,21 @@ func (ss *Sim) ConfigPats() {
 	//ss.TrainAll.AppendRows(ss.TestAC)
 	//ss.TrainAll.AppendRows(ss.TestLure)
 	ss.EnvRSA(ss.TrainAll, "TrainAll")
+
+	if ss.MST.On {
+		ss.ConfigMSTPats()
+	} else {
+		ss.TestMST.SetNumRows(0) // made on demand by MSTTest, for this run's patterns
+	}
+	if ss.AssocInf.On {
+		ss.ConfigAssocInfPats()
+	}
+	if ss.DirForget.On {
+		ss.ConfigDirForgetPats()
+	}
+	if exptype >= 2 {
+		ss.ConfigInterfPats()
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -2778,19 +3477,21 @@ func (ss *Sim) ValsTsr(name string) *etensor.Float32 {
 	return tsr
 }
 
-// RunName returns a name for this run that combines Tag and Params -- add this to
-// any file names that are saved.
+// RunName returns a name for this run that combines Tag, Params and any
+// non-default architecture variant -- add this to any file names that are saved.
 func (ss *Sim) RunName() string {
+	nm := ss.ParamsName()
 	if ss.Tag != "" {
-		pnm := ss.ParamsName()
-		if pnm == "Base" {
-			return ss.Tag
+		if nm == "Base" {
+			nm = ss.Tag
 		} else {
-			return ss.Tag + "_" + pnm
+			nm = ss.Tag + "_" + nm
 		}
-	} else {
-		return ss.ParamsName()
 	}
+	if av := ss.ArchVar(); av != &ArchVariants[0] {
+		nm += "_" + av.Name
+	}
+	return nm
 }
 
 // RunEpochName returns a string with the run and epoch numbers with leading zeros, suitable
@@ -2836,6 +3537,12 @@ func (ss *Sim) LogTrnTrl(dt *etable.Table) {
 	dt.SetCellFloat("TrgOnWasOff", row, ss.TrgOnWasOffCmp)
 	dt.SetCellFloat("TrgOnWasOffAll", row, ss.TrgOnWasOffAll)
 	dt.SetCellFloat("TrgOffWasOn", row, ss.TrgOffWasOn)
+	dt.SetCellFloat("ACh", row, float64(ss.TrlACh))
+	for i, pm := range ss.PrjnLrMod.All() {
+		if i < len(ss.LrModVals) {
+			dt.SetCellFloat(pm.Prjn+" LrMod", row, float64(ss.LrModVals[i]))
+		}
+	}
 
 	//JWA, adding so we can find this in simmat!!
 	for _, lnm := range ss.LayStatNms {
@@ -2853,44 +3560,15 @@ func (ss *Sim) LogTrnTrl(dt *etable.Table) {
 		}
 	}
 
-	lessLayStatNms := []string{"CA3"}
-	//end of Q1 is before DG input, end of Q2 is after 1/4 DG input, end of ActM (Q3) is guess, end of ActP (Q4) is +
-	//4 quarters are "ActQ1","ActQ2","ActM","ActP"
-	for _, lnm := range lessLayStatNms {
-		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
-		tsrq1 := ss.ValsTsr(lnm + "Q1")
-		tsrq4 := ss.ValsTsr(lnm + "ActP")
-		ly.UnitValsTensor(tsrq1, "ActQ1") // create tsr1 name, etc.
-		ly.UnitValsTensor(tsrq4, "ActP")
-		//ED := metric.Correlation32(tsrq1.Values, tsrq2.Values) // correlational difference
-		//ActPAvgEff is integrated over slow time constant, slowly progressing
-		actavgg := ly.Pools[0].Inhib.Act.Avg                                                //average for this single trial, effective, most stable
-		ED14 := metric.Abs32(tsrq1.Values, tsrq4.Values) / (actavgg * float32(tsrq4.Len())) // absolute difference, the error signal
-		//fmt.Printf("DG err before each trial: %v\n", ss.DGED12)
-		switch lnm {
-		case "CA3":
-			ss.CA3ED14 += float64(ED14)
-		}
-		//not currently using these, just aggregating above...
-		dt.SetCellFloat(lnm+" ED14", row, float64(ED14))
-	}
-
-	lessLayStatNms = []string{"ECout"} //
-	for _, lnm := range lessLayStatNms {
-		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
-		tsrq3 := ss.ValsTsr(lnm + "ActM")
-		tsrq4 := ss.ValsTsr(lnm + "ActP")
-		ly.UnitValsTensor(tsrq3, "ActM")
-		ly.UnitValsTensor(tsrq4, "ActP")
-		actavgg := ly.Pools[0].Inhib.Act.Avg                                                //average for this single trial, effective, most stable
-		ED34 := metric.Abs32(tsrq3.Values, tsrq4.Values) / (actavgg * float32(tsrq4.Len())) // absolute difference, the error signal
-		//fmt.Printf("DG err before each trial: %v\n", ss.DGED12)
-		switch lnm {
-		case "ECout":
-			ss.ECoutED34 += float64(ED34)
-		}
-		//not currently using these, just aggregating above...
-		dt.SetCellFloat(lnm+" ED34", row, float64(ED34))
+	for _, col := range ss.EDCols() {
+		ed := ss.TrlEDs[col]
+		ss.EDSums[col] += ed
+		dt.SetCellFloat(col, row, ed)
+	}
+
+	ss.StoreTrl("Trn", "", epc, ss.TrainEnv.TrialName.Cur)
+	if ss.BOLD.On {
+		ss.BOLDTrnTrl()
 	}
 
 	// note: essential to use Go version of update when called from another goroutine
@@ -2939,6 +3617,13 @@ func (ss *Sim) ConfigTrnTrlLog(dt *etable.Table) {
 		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
 		{"TrgOnWasOffAll", etensor.FLOAT64, nil, nil},
 		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
+		{"ACh", etensor.FLOAT64, nil, nil},
+	}
+	for _, col := range ss.EDCols() {
+		sch = append(sch, etable.Column{col, etensor.FLOAT64, nil, nil})
+	}
+	for _, pm := range ss.PrjnLrMod.All() {
+		sch = append(sch, etable.Column{pm.Prjn + " LrMod", etensor.FLOAT64, nil, nil})
 	}
 	//JWA, added for simmat
 	for _, lnm := range ss.LayStatNms {
@@ -2970,6 +3655,13 @@ func (ss *Sim) ConfigTrnTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("TrgOnWasOff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 	plt.SetColParams("TrgOnWasOffAll", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 	plt.SetColParams("TrgOffWasOn", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ACh", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	for _, col := range ss.EDCols() {
+		plt.SetColParams(col, eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	}
+	for _, pm := range ss.PrjnLrMod.All() {
+		plt.SetColParams(pm.Prjn+" LrMod", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
 
 	//JWA, added for simmat
 	for _, lnm := range ss.LayStatNms {
@@ -2995,111 +3687,6 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	epc := ss.TrainEnv.Epoch.Prv           // this is triggered by increment so use previous value
 	nt := float64(ss.TrainEnv.Table.Len()) // number of trials in view
 
-	//7_23_21, grab weights
-	hp := &ss.Hip
-	ecY := hp.ECSize.Y
-	ecX := hp.ECSize.X
-	plY := hp.ECPool.Y // good idea to get shorter vars when used frequently
-	plX := hp.ECPool.X // makes much more readable
-	ecin := ss.Net.LayerByName("ECin").(leabra.LeabraLayer).AsLeabra()
-	ca3 := ss.Net.LayerByName("CA3").(leabra.LeabraLayer).AsLeabra()
-
-	//ecin.SendPrjnVals(&ss.TmpValsWt, "Wt", ca3, 0, "qq") //another way, w/ send instead of rec
-	//fmt.Printf("Random send values: %v\n", ss.TmpValsWt)
-	// each output is ALL CA3 weights for a given ECin neuron. grab the average, log it, and move on!
-	wtm := []float32{}
-	for h := 0; h < (ecY * ecX * plY * plX); h++ {
-		ca3.RecvPrjnVals(&ss.TmpValsWtR, "Wt", ecin, h, "qq") //grab rec values from ECin
-		sum0 := float32(0)                                    //calculate means
-		for i := 0; i < len(ss.TmpValsWtR); i++ {
-			if !math.IsNaN(float64(ss.TmpValsWtR[i])) {
-				sum0 += (ss.TmpValsWtR[i])
-			}
-		}
-		sum0 /= float32(len(ss.TmpValsWtR))
-		wtm = append(wtm, sum0)
-	}
-	//fmt.Printf("avg wts!: %v\n", wtm)
-	//average within a pool!
-	wtmp := []float32{}
-	wtsp := []float32{}
-	for h := 0; h < (ecY * ecX); h++ {
-		poolavg := float32(0)
-		for i := h * plY * plX; i < (h+1)*plY*plX; i++ {
-			poolavg += wtm[i]
-		}
-		poolavg /= float32(plY * plX) //find mean in pool
-		wtmp = append(wtmp, poolavg)
-
-		//now calculate stdev
-		stdev := float64(0)
-		for i := h * plY * plX; i < (h+1)*plY*plX; i++ {
-			stdev += math.Pow(float64(wtm[i]-poolavg), 2)
-		}
-		// The use of Sqrt math function func Sqrt(x float64) float64
-		stdev = math.Sqrt(float64(stdev))
-		wtsp = append(wtsp, float32(stdev))
-	}
-	fnm := ss.LogFileName("wts-" + strconv.Itoa(ss.runnum) + "-" + strconv.Itoa(epc) + "_epc_wt") //save
-	file, err := os.Create(fnm)
-	_ = err
-	defer file.Close()
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-	a1 := []string{fmt.Sprintf("%f", wtmp)}
-	writer.Write(a1)
-	fnm = ss.LogFileName("wts_s-" + strconv.Itoa(ss.runnum) + "-" + strconv.Itoa(epc) + "_epc_wt")
-	file, err = os.Create(fnm)
-	_ = err
-	defer file.Close()
-	writer = csv.NewWriter(file)
-	defer writer.Flush()
-	a1 = []string{fmt.Sprintf("%f", wtsp)}
-	writer.Write(a1)
-
-	//implement decay; not used but keep in case a reviewer asks / important later
-	if ss.synap_decay == 1 {
-		//fmt.Printf("epc val: %v\n", epc)
-		//fmt.Printf("fillers epc: %v\n", ss.fillers[epc])
-		r := rand.New(rand.NewSource(int64(epc)))
-		ca3FmECin := ca3.RcvPrjns.SendName("ECin").(leabra.LeabraPrjn).AsLeabra() //grab projection
-		/*fmt.Printf("Random send values prjn 1 val: %v\n", ca3FmECin.SynVal("Wt", 0, 0)) //note this may be nan so may want to print > 1
-		fmt.Printf("Random send values prjn 1 val: %v\n", ca3FmECin.SynVal("Wt", 0, 1))
-		fmt.Printf("Random send values prjn 1 val: %v\n", ca3FmECin.SynVal("Wt", 0, 2))
-		fmt.Printf("Random send values prjn 1 val: %v\n", ca3FmECin.SynVal("Wt", 0, 3))
-		cumu := float64(0)*/
-		ndecays := 0
-		if epc < ss.MaxEpcs-1 { //between epochs, not at final
-			if ss.drifttype > 0 { //if not no drift condition
-				ndecays = ss.Pat.ListSize + ss.fillers[epc]
-			}
-		} else { // at final epoch
-			if ss.interval > 0 { //not the no RI drift either...
-				ndecays = ss.Pat.ListSize + ss.testlag // drift the amount of actest
-			}
-		}
-		if ndecays > 0 { //if in some kind of drift condition
-			fmt.Printf("decaying!\n")
-			for h := 0; h < (ecY * ecX * plY * plX); h++ { //ecin lay
-				for i := 0; i < hp.CA3Size.Y*hp.CA3Size.X; i++ {
-					sample := r.NormFloat64() * ss.decay_rate * float64(ndecays)
-					ca3FmECin.SetSynVal("Wt", h, i, ca3FmECin.SynVal("Wt", h, i)+float32(sample))
-					if ca3FmECin.SynVal("Wt", h, i) > 1 { //reset to 1
-						ca3FmECin.SetSynVal("Wt", h, i, 1)
-					} else if ca3FmECin.SynVal("Wt", h, i) < 0 { //reset to 0
-						ca3FmECin.SetSynVal("Wt", h, i, 0)
-					}
-				}
-			}
-		}
-		/*fmt.Printf("ndecays: %v\n", ndecays)
-		fmt.Printf("cumu: %v\n", cumu)
-		fmt.Printf("Random send values prjn 1 val post: %v\n", ca3FmECin.SynVal("Wt", 0, 0)) //note this may be nan so may want to print > 1
-		fmt.Printf("Random send values prjn 1 val post: %v\n", ca3FmECin.SynVal("Wt", 0, 1))
-		fmt.Printf("Random send values prjn 1 val post: %v\n", ca3FmECin.SynVal("Wt", 0, 2))
-		fmt.Printf("Random send values prjn 1 val post: %v\n", ca3FmECin.SynVal("Wt", 0, 3))*/
-	}
-
 	ss.EpcSSE = ss.SumSSE / nt
 	ss.SumSSE = 0
 	ss.EpcAvgSSE = ss.SumAvgSSE / nt
@@ -3132,13 +3719,10 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 		dt.SetCellFloat(ly.Nm+" ActAvg", row, float64(ly.Pools[0].ActAvg.ActPAvgEff))
 	}
 
-	//fmt.Printf("Avg DG err: %v\n", ss.DGED12/float64(nt))
-	dt.SetCellFloat("DG ED14", row, ss.DGED14/float64(nt))
-	dt.SetCellFloat("CA3 ED14", row, ss.CA3ED14/float64(nt))
-	dt.SetCellFloat("CA1 ED14", row, ss.CA1ED14/float64(nt))
-	dt.SetCellFloat("ECout ED34", row, ss.ECoutED34/float64(nt))
-	//reset to 0 after epoch
-	ss.DGED14, ss.CA3ED14, ss.CA1ED14, ss.ECoutED34 = 0, 0, 0, 0
+	for _, col := range ss.EDCols() {
+		dt.SetCellFloat(col, row, ss.EDSums[col]/float64(nt))
+		ss.EDSums[col] = 0 //reset to 0 after epoch
+	}
 
 	// note: essential to use Go version of update when called from another goroutine
 	if ss.TrnEpcPlot != nil {
@@ -3180,10 +3764,9 @@ func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
 		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
 		{"TrgOnWasOffAll", etensor.FLOAT64, nil, nil},
 		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
-		{"DG ED14", etensor.FLOAT64, nil, nil},    //JWA
-		{"CA3 ED14", etensor.FLOAT64, nil, nil},   //JWA
-		{"CA1 ED14", etensor.FLOAT64, nil, nil},   //JWA
-		{"ECout ED34", etensor.FLOAT64, nil, nil}, //JWA
+	}
+	for _, col := range ss.EDCols() {
+		sch = append(sch, etable.Column{col, etensor.FLOAT64, nil, nil})
 	}
 	for _, lnm := range ss.LayStatNms {
 		sch = append(sch, etable.Column{lnm + " ActAvg", etensor.FLOAT64, nil, nil})
@@ -3208,10 +3791,9 @@ func (ss *Sim) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("TrgOnWasOff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 	plt.SetColParams("TrgOnWasOffAll", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 	plt.SetColParams("TrgOffWasOn", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
-	plt.SetColParams("DG ED14", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
-	plt.SetColParams("CA3 ED14", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
-	plt.SetColParams("CA1 ED14", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
-	plt.SetColParams("ECout ED34", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	for _, col := range ss.EDCols() {
+		plt.SetColParams(col, eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	}
 	for _, lnm := range ss.LayStatNms {
 		plt.SetColParams(lnm+" ActAvg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 0.5)
 	}
@@ -3246,6 +3828,12 @@ func (ss *Sim) LogTstTrl(dt *etable.Table) {
 	dt.SetCellFloat("TrgOnWasOff", row, ss.TrgOnWasOffCmp)
 	dt.SetCellFloat("TrgOnWasOffAll", row, ss.TrgOnWasOffAll)
 	dt.SetCellFloat("TrgOffWasOn", row, ss.TrgOffWasOn)
+	dt.SetCellFloat("ACh", row, float64(ss.TrlACh))
+	dt.SetCellFloat("ECoutLat", row, ss.TrlECoutLat)
+	dt.SetCellFloat("CA3Lat", row, ss.TrlCA3Lat)
+	for _, col := range ss.EDCols() {
+		dt.SetCellFloat(col, row, ss.TrlEDs[col])
+	}
 
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -3261,6 +3849,10 @@ func (ss *Sim) LogTstTrl(dt *etable.Table) {
 		dt.SetCellTensor(lnm+"ActQ2", row, tsr)
 	}
 
+	ss.StoreTrl("Tst", ss.TestNm, epc, ss.TestEnv.TrialName.Cur)
+	ss.BOLDTstTrl()
+	ss.TraceWrite(epc)
+
 	// note: essential to use Go version of update when called from another goroutine
 	if ss.TstTrlPlot != nil {
 		ss.TstTrlPlot.GoUpdate()
@@ -3287,6 +3879,12 @@ func (ss *Sim) ConfigTstTrlLog(dt *etable.Table) {
 		{"TrgOnWasOff", etensor.FLOAT64, nil, nil},
 		{"TrgOnWasOffAll", etensor.FLOAT64, nil, nil},
 		{"TrgOffWasOn", etensor.FLOAT64, nil, nil},
+		{"ACh", etensor.FLOAT64, nil, nil},
+		{"ECoutLat", etensor.FLOAT64, nil, nil},
+		{"CA3Lat", etensor.FLOAT64, nil, nil},
+	}
+	for _, col := range ss.EDCols() {
+		sch = append(sch, etable.Column{col, etensor.FLOAT64, nil, nil})
 	}
 	for _, lnm := range ss.LayStatNms {
 		sch = append(sch, etable.Column{lnm + " ActM.Avg", etensor.FLOAT64, nil, nil})
@@ -3320,6 +3918,12 @@ func (ss *Sim) ConfigTstTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("TrgOnWasOff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)    //JWA, was On
 	plt.SetColParams("TrgOnWasOffAll", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1) //JWA, was On
 	plt.SetColParams("TrgOffWasOn", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)    //JWA, was On
+	plt.SetColParams("ACh", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("ECoutLat", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 100)
+	plt.SetColParams("CA3Lat", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 100)
+	for _, col := range ss.EDCols() {
+		plt.SetColParams(col, eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	}
 
 	for _, lnm := range ss.LayStatNms {
 		plt.SetColParams(lnm+" ActM.Avg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 0.5)
@@ -3490,6 +4094,10 @@ func (ss *Sim) LogTstEpc(dt *etable.Table) {
 	for _, ts := range ss.TstStatNms {
 		split.Agg(spl, ts, agg.AggMean)
 	}
+	edcols := ss.EDCols()
+	for _, col := range edcols {
+		split.Agg(spl, col, agg.AggMean)
+	}
 	ss.TstStats = spl.AggsToTable(etable.ColNameOnly)
 
 	for ri := 0; ri < ss.TstStats.Rows; ri++ {
@@ -3497,6 +4105,9 @@ func (ss *Sim) LogTstEpc(dt *etable.Table) {
 		for _, ts := range ss.TstStatNms {
 			dt.SetCellFloat(tst+" "+ts, row, ss.TstStats.CellFloat(ts, ri))
 		}
+		for _, col := range edcols {
+			dt.SetCellFloat(tst+" "+col, row, ss.TstStats.CellFloat(col, ri))
+		}
 	}
 
 	for _, lnm := range ss.LayStatNms {
@@ -3575,6 +4186,9 @@ func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
 		for _, ts := range ss.TstStatNms {
 			sch = append(sch, etable.Column{tn + " " + ts, etensor.FLOAT64, nil, nil})
 		}
+		for _, col := range ss.EDCols() {
+			sch = append(sch, etable.Column{tn + " " + col, etensor.FLOAT64, nil, nil})
+		}
 	}
 	for _, lnm := range ss.LayStatNms {
 		for _, ts := range ss.SimMatStats {
@@ -3606,6 +4220,9 @@ func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 				plt.SetColParams(tn+" "+ts, eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 			}
 		}
+		for _, col := range ss.EDCols() {
+			plt.SetColParams(tn+" "+col, eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+		}
 	}
 	// JWA where we add columns for HC layers
 	for _, lnm := range ss.LayStatNms {
@@ -3898,6 +4515,51 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TstCycPlot").(*eplot.Plot2D)
 	ss.TstCycPlot = ss.ConfigTstCycPlot(plt, ss.TstCycLog)
 
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "ReplayPlot").(*eplot.Plot2D)
+	ss.ReplayPlot = ss.ConfigReplayPlot(plt, ss.ReplayLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "PatSepPlot").(*eplot.Plot2D)
+	ss.PatSepPlot = ss.ConfigPatSepPlot(plt, ss.PatSepLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "MSTPlot").(*eplot.Plot2D)
+	ss.MSTPlot = ss.ConfigMSTPlot(plt, ss.MSTLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "AssocInfPlot").(*eplot.Plot2D)
+	ss.AssocInfPlot = ss.ConfigAssocInfPlot(plt, ss.AssocInfLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RIFPlot").(*eplot.Plot2D)
+	ss.RIFPlot = ss.ConfigRIFPlot(plt, ss.RIFLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "DirForgetPlot").(*eplot.Plot2D)
+	ss.DirFgtPlot = ss.ConfigDirForgetPlot(plt, ss.DirForgetLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "InterfPlot").(*eplot.Plot2D)
+	ss.InterfPlot = ss.ConfigInterfPlot(plt, ss.InterfLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TemporalPlot").(*eplot.Plot2D)
+	ss.TemporalPlot = ss.ConfigTemporalPlot(plt, ss.TemporalLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "DecodePlot").(*eplot.Plot2D)
+	ss.DecodePlot = ss.ConfigDecodePlot(plt, ss.DecodeLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RepDriftPlot").(*eplot.Plot2D)
+	ss.RepDriftPlot = ss.ConfigRepDriftPlot(plt, ss.RepDriftLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RDMPlot").(*eplot.Plot2D)
+	ss.RDMPlot = ss.ConfigRDMPlot(plt, ss.RDMLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "BOLDPlot").(*eplot.Plot2D)
+	ss.BOLDPlot = ss.ConfigBOLDPlot(plt, ss.BOLDLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "WtPoolPlot").(*eplot.Plot2D)
+	ss.WtPoolPlot = ss.ConfigWtPoolPlot(plt, ss.WtPoolLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "WtItemPlot").(*eplot.Plot2D)
+	ss.WtItemPlot = ss.ConfigWtItemPlot(plt, ss.WtItemLog)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "WtSimPlot").(*eplot.Plot2D)
+	ss.WtSimPlot = ss.ConfigWtSimPlot(plt, ss.WtSimLog)
+
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RunPlot").(*eplot.Plot2D)
 	ss.RunPlot = ss.ConfigRunPlot(plt, ss.RunLog)
 
@@ -4044,6 +4706,30 @@ func (ss *Sim) ConfigGui() *gi.Window {
 			ss.LogRunStats()
 		})
 
+	tbar.AddAction(gi.ActOpts{Label: "Pat Sep", Icon: "file-data", Tooltip: "run the pattern separation / completion harness -- see PatSep params and PatSepPlot"}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if !ss.IsRunning {
+				ss.IsRunning = true
+				tbar.UpdateActions()
+				go func() {
+					ss.PatSepCurves()
+					ss.Stopped()
+				}()
+			}
+		})
+
+	tbar.AddAction(gi.ActOpts{Label: "MST", Icon: "file-data", Tooltip: "run the mnemonic similarity test on the current network -- see MST params and MSTPlot"}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if !ss.IsRunning {
+				ss.IsRunning = true
+				tbar.UpdateActions()
+				go func() {
+					ss.MSTTest()
+					ss.Stopped()
+				}()
+			}
+		})
+
 	tbar.AddSeparator("misc")
 
 	tbar.AddAction(gi.ActOpts{Label: "New Seed", Icon: "new", Tooltip: "Generate a new initial random seed to get different results.  By default, Init re-establishes the same initial seed every time."}, win.This(),
@@ -4252,6 +4938,223 @@ func (ss *Sim) CmdArgs() {
 			defer ss.RunFile.Close()
 		}
 	}
+	if ss.Replay.On {
+		var err error
+		fnm := ss.LogFileName("replay")
+		ss.ReplayFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.ReplayFile = nil
+		} else {
+			fmt.Printf("Saving replay log to: %v\n", fnm)
+			defer ss.ReplayFile.Close()
+		}
+		fnm = ss.LogFileName("replay_cnt")
+		ss.RplCntFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.RplCntFile = nil
+		} else {
+			fmt.Printf("Saving replay counts to: %v\n", fnm)
+			defer ss.RplCntFile.Close()
+		}
+	}
+	if ss.PatSep.On {
+		var err error
+		fnm := ss.LogFileName("patsep")
+		ss.PatSepFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.PatSepFile = nil
+		} else {
+			fmt.Printf("Saving pattern separation curves to: %v\n", fnm)
+			defer ss.PatSepFile.Close()
+		}
+		ss.PatSepCurves()
+	}
+	if ss.MST.On {
+		var err error
+		fnm := ss.LogFileName("mst")
+		ss.MSTFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.MSTFile = nil
+		} else {
+			fmt.Printf("Saving mnemonic similarity test to: %v\n", fnm)
+			defer ss.MSTFile.Close()
+		}
+	}
+	if ss.AssocInf.On {
+		var err error
+		fnm := ss.LogFileName("associnf")
+		ss.AssocInfFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.AssocInfFile = nil
+		} else {
+			fmt.Printf("Saving associative inference tests to: %v\n", fnm)
+			defer ss.AssocInfFile.Close()
+		}
+	}
+	if ss.RIF.On {
+		var err error
+		fnm := ss.LogFileName("rif")
+		ss.RIFFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.RIFFile = nil
+		} else {
+			fmt.Printf("Saving retrieval-induced forgetting tests to: %v\n", fnm)
+			defer ss.RIFFile.Close()
+		}
+	}
+	if ss.DirForget.On {
+		var err error
+		fnm := ss.LogFileName("dirforget")
+		ss.DirFgtFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.DirFgtFile = nil
+		} else {
+			fmt.Printf("Saving directed forgetting tests to: %v\n", fnm)
+			defer ss.DirFgtFile.Close()
+		}
+	}
+	if ss.exptype >= 2 {
+		var err error
+		fnm := ss.LogFileName("interf")
+		ss.InterfFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.InterfFile = nil
+		} else {
+			fmt.Printf("Saving interference tests to: %v\n", fnm)
+			defer ss.InterfFile.Close()
+		}
+	}
+	if ss.Temporal.On {
+		var err error
+		fnm := ss.LogFileName("temporal")
+		ss.TemporalFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.TemporalFile = nil
+		} else {
+			fmt.Printf("Saving temporal memory tests to: %v\n", fnm)
+			defer ss.TemporalFile.Close()
+		}
+	}
+	if ss.Decode.On {
+		var err error
+		fnm := ss.LogFileName("decode")
+		ss.DecodeFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.DecodeFile = nil
+		} else {
+			fmt.Printf("Saving decoding accuracy to: %v\n", fnm)
+			defer ss.DecodeFile.Close()
+		}
+	}
+	if ss.RepDrift.On {
+		var err error
+		fnm := ss.LogFileName("repdrift")
+		ss.RepDriftFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.RepDriftFile = nil
+		} else {
+			fmt.Printf("Saving representational drift to: %v\n", fnm)
+			defer ss.RepDriftFile.Close()
+		}
+	}
+	if ss.RDM.Files != "" {
+		var err error
+		fnm := ss.LogFileName("rdmcmp")
+		ss.RDMFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.RDMFile = nil
+		} else {
+			fmt.Printf("Saving RDM comparisons to: %v\n", fnm)
+			defer ss.RDMFile.Close()
+		}
+	}
+	if ss.BOLD.On {
+		var err error
+		fnm := ss.LogFileName("bold")
+		ss.BOLDFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.BOLDFile = nil
+		} else {
+			fmt.Printf("Saving synthetic BOLD to: %v\n", fnm)
+			defer ss.BOLDFile.Close()
+		}
+	}
+	if ss.Trace.On() {
+		var err error
+		fnm := ss.TraceFileName()
+		ss.TraceBin, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.TraceBin = nil
+		} else {
+			fmt.Printf("Saving test traces to: %v\n", fnm)
+			defer ss.TraceBin.Close()
+		}
+		fnm = ss.LogFileName("trace")
+		ss.TraceFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.TraceFile = nil
+		} else {
+			fmt.Printf("Saving test trace index to: %v\n", fnm)
+			defer ss.TraceFile.Close()
+		}
+	}
+	if ss.WtSnap.On() {
+		ss.WtSnapFiles = map[string]*os.File{}
+		for _, pnm := range CommaList(ss.WtSnap.Prjns) {
+			fnm := ss.LogFileName("wts_" + pnm)
+			fl, err := os.Create(fnm)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			fmt.Printf("Saving %s weight snapshots to: %v\n", pnm, fnm)
+			ss.WtSnapFiles[pnm] = fl
+			defer fl.Close()
+		}
+		var err error
+		fnm := ss.LogFileName("wtpool")
+		ss.WtPoolFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.WtPoolFile = nil
+		} else {
+			fmt.Printf("Saving weight pool stats to: %v\n", fnm)
+			defer ss.WtPoolFile.Close()
+		}
+		fnm = ss.LogFileName("wtitem")
+		ss.WtItemFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.WtItemFile = nil
+		} else {
+			fmt.Printf("Saving item weight changes to: %v\n", fnm)
+			defer ss.WtItemFile.Close()
+		}
+		fnm = ss.LogFileName("wtsim")
+		ss.WtSimFile, err = os.Create(fnm)
+		if err != nil {
+			log.Println(err)
+			ss.WtSimFile = nil
+		} else {
+			fmt.Printf("Saving item weight similarity to: %v\n", fnm)
+			defer ss.WtSimFile.Close()
+		}
+	}
 	if ss.SaveWts {
 		fmt.Printf("Saving final weights per run\n")
 	}
@@ -0,0 +1,361 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/emer/emergent/patgen"
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// TemporalParams control the temporal memory tests: each studied cue is
+// presented alone (no target, no context), and the context it retrieves in
+// Layer is compared with the reference context of the test: the context at
+// the time of the test (ctxtT for the final test, the start of the next
+// session for the tests between sessions).  In the
+// temporal order test, of every two cues, the one with the less similar
+// retrieved context is judged to have come first.  In the judgment of
+// recency test, the similarity itself is the recency judgment.  Each cue is
+// timed by its last study session, in drift steps.
+type TemporalParams struct {
+	On    bool   `desc:"run the temporal order and judgment of recency tests after each test (command line)"`
+	Layer string `desc:"layer of the retrieved context: ECout (context pools only) or CA1"`
+	Pools []int  `desc:"for ECout, which context pools to compare (0 = fastest) -- empty = all"`
+}
+
+func (tp *TemporalParams) Defaults() {
+	tp.Layer = "ECout"
+}
+
+func (tp *TemporalParams) Update() {
+	switch tp.Layer {
+	case "ECout", "CA1":
+	default:
+		log.Printf("TemporalParams: unknown Layer: %s -- using ECout\n", tp.Layer)
+		tp.Layer = "ECout"
+	}
+}
+
+// TemporalEvent is one studied cue, as a row of cue vocabs, with the time of
+// its last study, for the temporal memory tests
+type TemporalEvent struct {
+	Cue  string  `desc:"cue vocab prefix (A, L2A)"`
+	Row  int     `desc:"row of the cue vocabs"`
+	Sess int     `desc:"last study session of the cue (0-based)"`
+	Time int     `desc:"time of the last study, in drift steps from the start of the first session"`
+	Sim  float64 `desc:"similarity of the retrieved context to the reference context"`
+}
+
+// StudyTime returns the time of row of study session epc, in drift steps
+// from the start of the first session
+func (ss *Sim) StudyTime(epc, row int) int {
+	t := row
+	for s := 0; s < epc; s++ {
+		t += ss.Pat.ListSize + ss.GapLen(s+1)
+	}
+	return t
+}
+
+// TemporalEvents returns the cues studied in the first nsess sessions, timed
+// by their last study session
+func (ss *Sim) TemporalEvents(nsess int) []*TemporalEvent {
+	if nsess > ss.MaxEpcs {
+		nsess = ss.MaxEpcs
+	}
+	type list struct {
+		cue        string
+		first, end int
+	}
+	lists := []list{{"A", 0, ss.MaxEpcs}}
+	switch {
+	case ss.DirForget.On:
+		n1 := ss.DirForgetL1Epcs()
+		lists = []list{{"A", 0, n1}, {"L2A", n1, ss.MaxEpcs}}
+	case ss.AssocInf.On:
+		lists[0].end = ss.AssocInfABEpcs()
+	}
+	var evs []*TemporalEvent
+	for _, l := range lists {
+		if nsess <= l.first {
+			continue
+		}
+		sess := l.end - 1
+		if nsess < l.end {
+			sess = nsess - 1
+		}
+		for r := 0; r < ss.Pat.ListSize; r++ {
+			evs = append(evs, &TemporalEvent{Cue: l.cue, Row: r, Sess: sess, Time: ss.StudyTime(sess, r)})
+		}
+	}
+	return evs
+}
+
+// TemporalCtxt returns the context region of vals, for ECout unit vals:
+// the context pools in Temporal.Pools
+func (ss *Sim) TemporalCtxt(vals []float32) []float32 {
+	plsz := ss.Hip.ECPool.Y * ss.Hip.ECPool.X
+	st := 2 * ss.wpvc * plsz // context pools, as in MemStats
+	pools := ss.Temporal.Pools
+	if len(pools) == 0 {
+		for i := 0; i < ss.cvcn*2; i++ {
+			pools = append(pools, i)
+		}
+	}
+	var ctxt []float32
+	for _, p := range pools {
+		ctxt = append(ctxt, vals[st+p*plsz:st+(p+1)*plsz]...)
+	}
+	return ctxt
+}
+
+// TemporalPats makes the patterns for the cues of vocab prefix cue
+// presented alone, for retrieving their context
+func (ss *Sim) TemporalPats(cue string) *etable.Table {
+	hp := &ss.Hip
+	var inp []string
+	for i := 1; i <= 4; i++ {
+		inp = append(inp, cue+strconv.Itoa(i))
+	}
+	for i := 0; i < 4+ss.cvcn*2; i++ {
+		inp = append(inp, "empty")
+	}
+	dt := &etable.Table{}
+	patgen.InitPats(dt, "Temporal_"+cue+"_", "Temporal cue-only Pats", "Input", "ECout", ss.Pat.ListSize, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+	patgen.MixPats(dt, ss.PoolVocab, "ECout", inp)
+	return dt
+}
+
+// TestTime returns the time of the test after study session epc-1 (epc =
+// MaxEpcs for the final test), following the gap, in drift steps from the
+// start of the first session
+func (ss *Sim) TestTime(epc int) int {
+	return ss.StudyTime(epc-1, ss.Pat.ListSize-1) + ss.GapLen(epc)
+}
+
+// TemporalRefCtxt returns the name of the reference context vocab of the
+// test after study session epc-1, for context pool i: the test context
+// (ctxtT) for the final test, and the context of the next study session,
+// which starts after the same gap, for the tests between sessions
+func (ss *Sim) TemporalRefCtxt(epc, i int) string {
+	if epc < ss.MaxEpcs {
+		if nm := SessionCtxt(epc, i); ss.PoolVocab[nm] != nil {
+			return nm
+		}
+	}
+	return fmt.Sprintf("ctxtT_%d", i+1)
+}
+
+// TemporalRef returns the reference context of the test after study session
+// epc-1: the first row of the TemporalRefCtxt for ECout, or the CA1 activity
+// it evokes alone for CA1
+func (ss *Sim) TemporalRef(epc int) []float32 {
+	hp := &ss.Hip
+	var inp []string
+	for i := 0; i < 8; i++ {
+		inp = append(inp, "empty")
+	}
+	for i := 0; i < ss.cvcn*2; i++ {
+		inp = append(inp, ss.TemporalRefCtxt(epc, i))
+	}
+	dt := &etable.Table{}
+	patgen.InitPats(dt, "TemporalRef_", "Temporal reference context Pats", "Input", "ECout", ss.Pat.ListSize, hp.ECSize.Y, hp.ECSize.X, hp.ECPool.Y, hp.ECPool.X)
+	patgen.MixPats(dt, ss.PoolVocab, "Input", inp)
+	if ss.Temporal.Layer == "ECout" {
+		return ss.TemporalCtxt(dt.CellTensor("Input", 0).(*etensor.Float32).Values)
+	}
+	input := ss.Net.LayerByName("Input").(leabra.LeabraLayer).AsLeabra()
+	ly := ss.Net.LayerByName(ss.Temporal.Layer).(leabra.LeabraLayer).AsLeabra()
+	ss.Net.InitActs()
+	ss.Net.InitExt()
+	input.ApplyExt(dt.CellTensor("Input", 0))
+	ss.SettleCyc(false, false)
+	var ref []float32
+	ly.UnitVals(&ref, "ActM")
+	return ref
+}
+
+// TemporalBin returns the log2 bin of lag t (1, 2, 4, 8, ...)
+func TemporalBin(t int) float64 {
+	if t < 1 {
+		return 0
+	}
+	return math.Pow(2, math.Floor(math.Log2(float64(t))))
+}
+
+// TemporalTest runs the temporal order and judgment of recency tests on the
+// cues studied so far, without learning, and logs the order accuracy by lag
+// and by session separation, and the recency judgments by age, to TemporalLog
+func (ss *Sim) TemporalTest() {
+	epc := ss.TrainEnv.Epoch.Cur
+	if epc > ss.MaxEpcs {
+		epc = ss.MaxEpcs
+	}
+	evs := ss.TemporalEvents(epc)
+	if len(evs) == 0 {
+		return
+	}
+	ref := ss.TemporalRef(epc)
+	ly := ss.Net.LayerByName(ss.Temporal.Layer).(leabra.LeabraLayer).AsLeabra()
+	for st := 0; st < len(evs); st += ss.Pat.ListSize {
+		ss.TestTable(ss.TemporalPats(evs[st].Cue), func() {
+			row := ss.TestEnv.Table.Idxs[ss.TestEnv.Trial.Cur]
+			ly.UnitVals(&ss.TmpVals, "ActM")
+			vals := ss.TmpVals
+			if ss.Temporal.Layer == "ECout" {
+				vals = ss.TemporalCtxt(vals)
+			}
+			evs[st+row].Sim = float64(metric.Cosine32(vals, ref))
+		})
+	}
+
+	// order: of each two cues, the one more similar to the reference is more recent
+	type bin struct{ n, acc, sim float64 }
+	lagb := map[float64]*bin{}
+	sepb := map[float64]*bin{}
+	add := func(mp map[float64]*bin, k, acc, sim float64) {
+		b, has := mp[k]
+		if !has {
+			b = &bin{}
+			mp[k] = b
+		}
+		b.n++
+		b.acc += acc
+		b.sim += sim
+	}
+	for i, e1 := range evs {
+		for _, e2 := range evs[i+1:] {
+			if e1.Time == e2.Time {
+				continue
+			}
+			early, late := e1, e2
+			if e2.Time < e1.Time {
+				early, late = e2, e1
+			}
+			acc := 0.0
+			switch {
+			case late.Sim > early.Sim:
+				acc = 1
+			case late.Sim == early.Sim:
+				acc = 0.5
+			}
+			add(lagb, TemporalBin(late.Time-early.Time), acc, 0)
+			add(sepb, float64(late.Sess-early.Sess), acc, 0)
+		}
+	}
+
+	// recency: similarity to the reference by age at test
+	tst := ss.TestTime(epc)
+	ageb := map[float64]*bin{}
+	sims := make([]float64, len(evs))
+	rec := make([]float64, len(evs))
+	for i, e := range evs {
+		age := tst - e.Time
+		add(ageb, TemporalBin(age), 0, e.Sim)
+		sims[i] = e.Sim
+		rec[i] = -math.Log2(float64(age + 1))
+	}
+
+	dt := ss.TemporalLog
+	for _, by := range []struct {
+		task, by string
+		mp       map[float64]*bin
+	}{{"Order", "Lag", lagb}, {"Order", "Sep", sepb}, {"JOR", "Age", ageb}} {
+		var keys []float64
+		for k := range by.mp {
+			keys = append(keys, k)
+		}
+		sort.Float64s(keys)
+		for _, k := range keys {
+			b := by.mp[k]
+			if by.task == "Order" {
+				ss.LogTemporal(dt, by.task, by.by, k, b.n, b.acc/b.n, math.NaN(), math.NaN())
+			} else {
+				ss.LogTemporal(dt, by.task, by.by, k, b.n, math.NaN(), b.sim/b.n, math.NaN())
+			}
+		}
+	}
+	ss.LogTemporal(dt, "JOR", "All", 0, float64(len(evs)), math.NaN(), math.NaN(), metric.Correlation64(sims, rec))
+}
+
+//////////////////////////////////////////////
+//  TemporalLog
+
+// LogTemporal adds one bin of a temporal memory test to the TemporalLog
+func (ss *Sim) LogTemporal(dt *etable.Table, task, by string, bin, n, acc, sim, corr float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ss.TrainEnv.Epoch.Prv))
+	dt.SetCellString("Layer", row, ss.Temporal.Layer)
+	dt.SetCellString("Task", row, task)
+	dt.SetCellString("By", row, by)
+	dt.SetCellFloat("Bin", row, bin)
+	dt.SetCellFloat("N", row, n)
+	dt.SetCellFloat("Acc", row, acc)
+	dt.SetCellFloat("RefSim", row, sim)
+	dt.SetCellFloat("Corr", row, corr)
+
+	if ss.TemporalPlot != nil {
+		ss.TemporalPlot.GoUpdate()
+	}
+	if ss.TemporalFile != nil {
+		if !ss.TemporalHdrs {
+			dt.WriteCSVHeaders(ss.TemporalFile, etable.Tab)
+			ss.TemporalHdrs = true
+		}
+		dt.WriteCSVRow(ss.TemporalFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigTemporalLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TemporalLog")
+	dt.SetMetaData("desc", "Temporal order accuracy by lag (Lag, drift steps, log2 bins) and session separation (Sep), and judgment of recency: reference context similarity by age (Age, log2 bins), and its correlation (Corr) with recency (All)")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Task", etensor.STRING, nil, nil},
+		{"By", etensor.STRING, nil, nil},
+		{"Bin", etensor.FLOAT64, nil, nil},
+		{"N", etensor.FLOAT64, nil, nil},
+		{"Acc", etensor.FLOAT64, nil, nil},
+		{"RefSim", etensor.FLOAT64, nil, nil},
+		{"Corr", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigTemporalPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Temporal Memory Plot"
+	plt.Params.XAxisCol = "Bin"
+	plt.Params.LegendCol = "By"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Layer", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Task", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("By", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Bin", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("N", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Acc", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("RefSim", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Corr", eplot.Off, eplot.FixMin, -1, eplot.FixMax, 1)
+	return plt
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+
+	"github.com/emer/leabra/leabra"
+)
+
+// ThetaScale sets the WtScale of one projection for a theta phase
+type ThetaScale struct {
+	Prjn       string  `desc:"projection name, e.g., CA3ToCA1"`
+	Abs        float32 `desc:"WtScale.Abs during the phase (multiplied by any Scale lesions) -- negative = leave unchanged"`
+	Rel        float32 `desc:"WtScale.Rel during the phase, before RelDel / RelDelTest -- negative = the projection's base WtScale.Rel"`
+	RelDel     float32 `desc:"amount subtracted from the projection's base WtScale.Rel during training"`
+	RelDelTest float32 `desc:"amount subtracted from the projection's base WtScale.Rel during testing"`
+}
+
+// UnmarshalJSON defaults Rel to the base WtScale.Rel when it is not in the
+// schedule file
+func (sc *ThetaScale) UnmarshalJSON(b []byte) error {
+	type plain ThetaScale
+	p := plain{Rel: -1}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*sc = ThetaScale(p)
+	return nil
+}
+
+// ThetaPhase is one phase of the theta schedule run by AlphaCyc.
+// Scales and Clamp take effect at the start of the phase, and persist
+// until changed by a later phase.
+type ThetaPhase struct {
+	Name     string       `desc:"name of the phase, for reference"`
+	Cycles   int          `desc:"number of cycles in the phase -- 0 = Time.CycPerQtr"`
+	QtrEnd   bool         `desc:"phase ends a leabra quarter (QuarterFinal) -- the 3rd quarter end is the minus phase (ActM), the 4th the plus phase (ActP) -- set false for sub-phases within a quarter"`
+	Scales   []ThetaScale `desc:"projection scales set at the start of the phase"`
+	Clamp    string       `desc:"layer whose Act is clamped onto ECout at the start of the phase, during training only -- empty = no change"`
+	MemStats bool         `desc:"compute MemStats at the end of this phase (requires QtrEnd)"`
+}
+
+// ThetaParams hold the theta-phase schedule for AlphaCyc.
+// If Phases is empty, the standard hippocampal schedule is used (see StdTheta).
+type ThetaParams struct {
+	File   string       `desc:"JSON file with the Phases schedule, loaded in Update -- empty = none"`
+	Phases []ThetaPhase `desc:"theta-phase schedule -- empty = standard schedule from Hip params"`
+}
+
+func (tp *ThetaParams) Defaults() {
+	tp.Phases = nil
+}
+
+func (tp *ThetaParams) Update() {
+	if tp.File != "" {
+		tp.OpenJSON(tp.File)
+	}
+}
+
+// OpenJSON loads the Phases schedule from given JSON file
+func (tp *ThetaParams) OpenJSON(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	var phs []ThetaPhase
+	if err = json.Unmarshal(b, &phs); err != nil {
+		log.Println(err)
+		return err
+	}
+	tp.Phases = phs
+	return nil
+}
+
+// SaveThetaJSON saves the schedule that AlphaCyc would use to given JSON file,
+// as a starting point for editing
+func (ss *Sim) SaveThetaJSON(filename string) error {
+	b, err := json.MarshalIndent(ss.ThetaSchedule(), "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// StdTheta returns the standard hippocampal theta schedule, using the
+// current Hip params:
+//
+//	Q1:    CA1 driven by ECin; mossy fibers reduced by MossyDel (if edl)
+//	Q2-Q3: CA1 driven by CA3 recall; full mossy fibers (less MossyDelTest when testing)
+//	Q4:    CA1 back to ECin drive only; ECout clamped from ECin
+func (ss *Sim) StdTheta() []ThetaPhase {
+	mdel := float32(0)
+	if ss.edl == 1 { // 0 for the first quarter
+		mdel = ss.Hip.MossyDel
+	}
+	return []ThetaPhase{
+		{Name: "Q1", QtrEnd: true, Scales: []ThetaScale{
+			{Prjn: "ECinToCA1", Abs: 1, Rel: -1},
+			{Prjn: "CA3ToCA1", Abs: 0, Rel: -1},
+			{Prjn: "DGToCA3", Abs: -1, Rel: -1, RelDel: mdel, RelDelTest: mdel},
+		}},
+		{Name: "Q2", QtrEnd: true, Scales: []ThetaScale{
+			{Prjn: "ECinToCA1", Abs: 0, Rel: -1},
+			{Prjn: "CA3ToCA1", Abs: 1, Rel: -1},
+			{Prjn: "DGToCA3", Abs: -1, Rel: -1, RelDel: 0, RelDelTest: ss.Hip.MossyDelTest},
+		}},
+		{Name: "Q3", QtrEnd: true, MemStats: true},
+		{Name: "Q4", QtrEnd: true, Clamp: "ECin", Scales: []ThetaScale{
+			{Prjn: "ECinToCA1", Abs: 1, Rel: -1},
+			{Prjn: "CA3ToCA1", Abs: 0, Rel: -1},
+		}},
+	}
+}
+
+// ThetaSchedule returns the theta schedule for AlphaCyc
+func (ss *Sim) ThetaSchedule() []ThetaPhase {
+	if len(ss.Theta.Phases) > 0 {
+		return ss.Theta.Phases
+	}
+	return ss.StdTheta()
+}
+
+// SaveThetaScales records the WtScale of all projections in the schedule,
+// and returns a function that restores them
+func (ss *Sim) SaveThetaScales(sched []ThetaPhase) func() {
+	type sv struct {
+		pj       *leabra.Prjn
+		abs, rel float32
+	}
+	var svs []sv
+	if ss.ThetaRel == nil {
+		ss.ThetaRel = make(map[string]float32)
+	}
+	for k := range ss.ThetaRel {
+		delete(ss.ThetaRel, k)
+	}
+	for pi := range sched {
+		for _, sc := range sched[pi].Scales {
+			if _, has := ss.ThetaRel[sc.Prjn]; has {
+				continue
+			}
+			pj := ss.PrjnByName(sc.Prjn)
+			if pj == nil {
+				log.Printf("ThetaSchedule: projection not found: %s\n", sc.Prjn)
+				continue
+			}
+			ss.ThetaRel[sc.Prjn] = pj.WtScale.Rel
+			svs = append(svs, sv{pj, pj.WtScale.Abs, pj.WtScale.Rel})
+		}
+	}
+	return func() {
+		for _, s := range svs {
+			s.pj.WtScale.Abs = s.abs
+			s.pj.WtScale.Rel = s.rel
+		}
+	}
+}
+
+// ApplyThetaScales sets the projection scales for the start of given phase,
+// relative to the base Rel values recorded by SaveThetaScales
+func (ss *Sim) ApplyThetaScales(ph *ThetaPhase, train bool) {
+	for _, sc := range ph.Scales {
+		rel, has := ss.ThetaRel[sc.Prjn]
+		if !has {
+			continue
+		}
+		pj := ss.PrjnByName(sc.Prjn)
+		if sc.Abs >= 0 {
+			pj.WtScale.Abs = sc.Abs * ss.LesionScale(pj, train)
+		}
+		if sc.Rel >= 0 {
+			rel = sc.Rel
+		}
+		if train {
+			pj.WtScale.Rel = rel - sc.RelDel
+		} else {
+			pj.WtScale.Rel = rel - sc.RelDelTest
+		}
+	}
+}
+
+// ThetaClamp clamps the Act of layer lnm onto ECout
+func (ss *Sim) ThetaClamp(lnm string) {
+	lyi := ss.Net.LayerByName(lnm)
+	if lyi == nil {
+		log.Printf("ThetaClamp: layer not found: %s\n", lnm)
+		return
+	}
+	ecout := ss.Net.LayerByName("ECout").(leabra.LeabraLayer).AsLeabra()
+	lyi.(leabra.LeabraLayer).AsLeabra().UnitVals(&ss.TmpVals, "Act") // note: could use input instead -- not much diff
+	ecout.ApplyExt1D32(ss.TmpVals)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// TraceParams control the test trace recorder, which saves the unit values
+// of chosen layers on every cycle of all four quarters of chosen test
+// trials, for looking at the settling of single trials without the GUI.
+//
+// Traces are written (headless only) to a binary file of little-endian
+// float32 values, named as the logs with a .bin extension: for each traced
+// trial and layer, Cycles blocks of Units values, in unit (Neurons) order.
+// Each block of Cycles x Units is described by a row of the trace index log
+// (file "trace"), with its byte Offset in the binary file and the Shape of
+// the layer.
+type TraceParams struct {
+	Items  string `desc:"comma-separated test trials to trace, by TrialName or item number, or all -- empty = off (command line)"`
+	Layers string `desc:"comma-separated layers to trace (command line)"`
+	Tests  string `desc:"comma-separated tests (TestNm) to trace -- empty = all"`
+	Final  bool   `desc:"only trace the test after the final study session"`
+	Var    string `desc:"unit variable to trace"`
+}
+
+func (tp *TraceParams) Defaults() {
+	tp.Layers = "CA3,ECout"
+	tp.Var = "Act"
+}
+
+func (tp *TraceParams) Update() {
+}
+
+// On returns true if any trials are traced
+func (tp *TraceParams) On() bool {
+	return tp.Items != ""
+}
+
+// CommaList returns the non-empty, trimmed elements of a comma-separated list
+func CommaList(s string) []string {
+	var lst []string
+	for _, el := range strings.Split(s, ",") {
+		if el = strings.TrimSpace(el); el != "" {
+			lst = append(lst, el)
+		}
+	}
+	return lst
+}
+
+// TraceFileName returns the name of the binary trace file
+func (ss *Sim) TraceFileName() string {
+	return strings.TrimSuffix(ss.LogFileName("trace"), ".tsv") + ".bin"
+}
+
+// Traced returns whether the current test trial is traced
+func (ss *Sim) Traced() bool {
+	tp := &ss.Trace
+	if tp.Final && ss.TrainEnv.Epoch.Cur < ss.MaxEpcs {
+		return false
+	}
+	if tests := CommaList(tp.Tests); len(tests) > 0 {
+		ok := false
+		for _, tn := range tests {
+			if tn == ss.TestNm {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	nm := ss.TestEnv.TrialName.Cur
+	for _, it := range CommaList(tp.Items) {
+		if it == "all" || it == nm {
+			return true
+		}
+		if n, err := strconv.Atoi(it); err == nil && TrialItem(nm) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// TraceCyc records the unit values of the traced layers at given cycle of
+// a traced test trial -- called from AlphaCyc every test cycle
+func (ss *Sim) TraceCyc(cyc int) {
+	if !ss.Trace.On() || ss.TraceBin == nil {
+		return
+	}
+	if cyc == 0 {
+		ss.TraceTrl = ss.Traced()
+		ss.TraceCycs = 0
+		if ss.TraceBufs == nil {
+			ss.TraceBufs = map[string][]float32{}
+		}
+		for lnm := range ss.TraceBufs {
+			ss.TraceBufs[lnm] = ss.TraceBufs[lnm][:0]
+		}
+	}
+	if !ss.TraceTrl {
+		return
+	}
+	for _, lnm := range CommaList(ss.Trace.Layers) {
+		ly, ok := ss.Net.LayerByName(lnm).(leabra.LeabraLayer)
+		if !ok {
+			continue
+		}
+		lay := ly.AsLeabra()
+		vi, err := lay.UnitVarIdx(ss.Trace.Var)
+		if err != nil {
+			continue
+		}
+		buf := ss.TraceBufs[lnm]
+		for ni := range lay.Neurons {
+			buf = append(buf, lay.UnitVal1D(vi, ni))
+		}
+		ss.TraceBufs[lnm] = buf
+	}
+	ss.TraceCycs++
+}
+
+// TraceWrite writes the traces of the current test trial, if traced, to the
+// binary trace file and the trace index log -- called from LogTstTrl
+func (ss *Sim) TraceWrite(epc int) {
+	if !ss.TraceTrl || ss.TraceBin == nil {
+		return
+	}
+	ss.TraceTrl = false
+	for _, lnm := range CommaList(ss.Trace.Layers) {
+		buf := ss.TraceBufs[lnm]
+		if len(buf) == 0 {
+			continue
+		}
+		lay := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		off := ss.TraceOff
+		if err := binary.Write(ss.TraceBin, binary.LittleEndian, buf); err != nil {
+			log.Println(err)
+			ss.TraceBin = nil
+			return
+		}
+		ss.TraceOff += int64(4 * len(buf))
+		ss.LogTrace(ss.TraceLog, epc, lay, off)
+	}
+}
+
+//////////////////////////////////////////////
+//  TraceLog
+
+// LogTrace adds the index of one traced layer of the current test trial to the TraceLog
+func (ss *Sim) LogTrace(dt *etable.Table, epc int, lay *leabra.Layer, off int64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	shp := make([]string, len(lay.Shp.Shp))
+	for i, d := range lay.Shp.Shp {
+		shp[i] = strconv.Itoa(d)
+	}
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellString("TestNm", row, ss.TestNm)
+	dt.SetCellString("TrialName", row, ss.TestEnv.TrialName.Cur)
+	dt.SetCellString("Layer", row, lay.Nm)
+	dt.SetCellString("Var", row, ss.Trace.Var)
+	dt.SetCellFloat("Offset", row, float64(off))
+	dt.SetCellFloat("Cycles", row, float64(ss.TraceCycs))
+	dt.SetCellFloat("Units", row, float64(len(lay.Neurons)))
+	dt.SetCellString("Shape", row, strings.Join(shp, "x"))
+
+	if ss.TraceFile != nil {
+		if !ss.TraceHdrs {
+			dt.WriteCSVHeaders(ss.TraceFile, etable.Tab)
+			ss.TraceHdrs = true
+		}
+		dt.WriteCSVRow(ss.TraceFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigTraceLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TraceLog")
+	dt.SetMetaData("desc", "Index of the test traces: byte Offset in the binary trace file of the Cycles x Units float32 values of each traced layer and trial")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"TestNm", etensor.STRING, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Var", etensor.STRING, nil, nil},
+		{"Offset", etensor.INT64, nil, nil},
+		{"Cycles", etensor.INT64, nil, nil},
+		{"Units", etensor.INT64, nil, nil},
+		{"Shape", etensor.STRING, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
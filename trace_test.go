@@ -0,0 +1,28 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommaList(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"CA3", []string{"CA3"}},
+		{"CA3,DG", []string{"CA3", "DG"}},
+		{" CA3 ,, DG ,", []string{"CA3", "DG"}},
+	}
+	for _, tt := range tests {
+		if got := CommaList(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommaList(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
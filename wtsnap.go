@@ -0,0 +1,564 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/emer/etable/eplot"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/emer/leabra/leabra"
+)
+
+// WtSnapParams control weight snapshots of chosen projections at points of
+// the study schedule, and the analyses of the weight changes between them.
+//
+// Each snapshot is the full Recv x Send weight matrix (NaN where there is no
+// synapse), written as a row of a per-projection etable file (wts_<Prjn>),
+// which can be loaded back with etable OpenCSV.  Points are:
+//
+//	Init:    before the first study session
+//	Study:   at the end of each study session
+//	Offline: after the offline phases (replay, filler, decay) following each
+//	         session, just before the test
+//
+// At each snapshot, relative to the previous one of the projection:
+//
+//	WtPoolLog: mean and SD of the weights, and mean change, per pool of the
+//	           sending and receiving layers
+//	WtItemLog: mean change of the TopK synapses of each item, those with the
+//	           largest sender x receiver ActM coactivity in its last study
+//	           trial of the session, and of all synapses
+//	WtSimLog:  similarity of the weight fingerprints of items (the mean
+//	           weights to each receiving unit from the sending units active in
+//	           the item), same item and different items, with those of each
+//	           earlier snapshot
+//
+// The item analyses need the ActM of both layers in TrnTrlLog (LayStatNms).
+type WtSnapParams struct {
+	Prjns  string  `desc:"comma-separated projections to snapshot (e.g., ECinToCA3,CA3ToCA3) -- empty = off (command line)"`
+	Points string  `desc:"comma-separated schedule points at which to snapshot: Init, Study, Offline"`
+	TopK   int     `min:"1" desc:"number of synapses per item, by sender x receiver coactivity at study, for the item weight change"`
+	ActThr float64 `desc:"sending ActM above which a unit is active in an item, for the item weight fingerprints"`
+}
+
+func (wp *WtSnapParams) Defaults() {
+	wp.Points = "Init,Study"
+	wp.TopK = 20
+	wp.ActThr = 0.5
+}
+
+func (wp *WtSnapParams) Update() {
+	if wp.TopK < 1 {
+		wp.TopK = 1
+	}
+}
+
+// On returns true if any projections are snapshot
+func (wp *WtSnapParams) On() bool {
+	return wp.Prjns != ""
+}
+
+// HasPoint returns true if snapshots are taken at given schedule point
+func (wp *WtSnapParams) HasPoint(pt string) bool {
+	for _, p := range CommaList(wp.Points) {
+		if p == pt {
+			return true
+		}
+	}
+	return false
+}
+
+// WtFPSet is the item weight fingerprints of one snapshot of a projection
+type WtFPSet struct {
+	Sess  int
+	Point string
+	Items map[int][]float64
+}
+
+// PrjnWts returns the weights of pj as a dense Recv x Send matrix, in
+// row-major order, with NaN where there is no synapse
+func PrjnWts(pj *leabra.Prjn) (wts []float32, nr, ns int) {
+	ns = len(pj.Send.(leabra.LeabraLayer).AsLeabra().Neurons)
+	nr = len(pj.Recv.(leabra.LeabraLayer).AsLeabra().Neurons)
+	wts = make([]float32, nr*ns)
+	nan := float32(math.NaN())
+	for i := range wts {
+		wts[i] = nan
+	}
+	for si := 0; si < ns; si++ {
+		nc := int(pj.SConN[si])
+		st := int(pj.SConIdxSt[si])
+		for ci := 0; ci < nc; ci++ {
+			wts[int(pj.SConIdx[st+ci])*ns+si] = pj.Syns[st+ci].Wt
+		}
+	}
+	return
+}
+
+// LayPool returns the pool of unit ni of ly, and the number of pools --
+// layers without pools are one pool
+func LayPool(ly *leabra.Layer, ni int) (pi, np int) {
+	if !ly.Is4D() {
+		return 0, 1
+	}
+	return ni / (ly.Shp.Dim(2) * ly.Shp.Dim(3)), ly.Shp.Dim(0) * ly.Shp.Dim(1)
+}
+
+// WtItemPats returns the ActM of layer lnm on the last study trial of each
+// item in TrnTrlLog, by item, or nil if not logged
+func (ss *Sim) WtItemPats(lnm string) map[int][]float64 {
+	dt := ss.TrnTrlLog
+	col := lnm + "ActM"
+	if dt.ColIdx(col) < 0 || dt.Rows == 0 {
+		return nil
+	}
+	pats := map[int][]float64{}
+	for r := 0; r < dt.Rows; r++ {
+		pats[TrialItem(dt.CellString("TrialName", r))] = dt.CellTensor(col, r).(*etensor.Float64).Values
+	}
+	return pats
+}
+
+// WtSnapshot takes the snapshots of all WtSnap projections at given schedule
+// point of session sess, and runs the weight change analyses
+func (ss *Sim) WtSnapshot(point string, sess int) {
+	wp := &ss.WtSnap
+	if !wp.On() || ss.PreTraining || !wp.HasPoint(point) {
+		return
+	}
+	if ss.WtSnapPrv == nil {
+		ss.WtSnapPrv = map[string][]float32{}
+		ss.WtFPs = map[string][]*WtFPSet{}
+	}
+	for _, pnm := range CommaList(wp.Prjns) {
+		pj := ss.PrjnByName(pnm)
+		if pj == nil {
+			continue
+		}
+		wts, nr, ns := PrjnWts(pj)
+		ss.LogWtSnap(pnm, sess, point, wts, nr, ns)
+		prv := ss.WtSnapPrv[pnm]
+		ss.WtPools(pj, sess, point, wts, prv)
+		if point != "Init" {
+			spats := ss.WtItemPats(pj.Send.Name())
+			rpats := ss.WtItemPats(pj.Recv.Name())
+			if spats != nil && rpats != nil {
+				if prv != nil {
+					ss.WtItems(pnm, sess, point, wts, prv, nr, ns, spats, rpats)
+				}
+				ss.WtFingerprints(pnm, sess, point, wts, nr, ns, spats)
+			}
+		}
+		ss.WtSnapPrv[pnm] = wts
+	}
+}
+
+// WtPools logs the mean and SD of the weights of pj per pool of its sending
+// and receiving layers, and their mean change from prv (if not nil)
+func (ss *Sim) WtPools(pj *leabra.Prjn, sess int, point string, wts, prv []float32) {
+	slay := pj.Send.(leabra.LeabraLayer).AsLeabra()
+	rlay := pj.Recv.(leabra.LeabraLayer).AsLeabra()
+	ns := len(slay.Neurons)
+	for _, side := range []string{"Send", "Recv"} {
+		ly := slay
+		if side == "Recv" {
+			ly = rlay
+		}
+		_, np := LayPool(ly, 0)
+		sum := make([]float64, np)
+		ssq := make([]float64, np)
+		dsum := make([]float64, np)
+		n := make([]float64, np)
+		for i, w := range wts {
+			if math.IsNaN(float64(w)) {
+				continue
+			}
+			ni := i % ns
+			if side == "Recv" {
+				ni = i / ns
+			}
+			pi, _ := LayPool(ly, ni)
+			sum[pi] += float64(w)
+			ssq[pi] += float64(w) * float64(w)
+			if prv != nil {
+				dsum[pi] += float64(w - prv[i])
+			}
+			n[pi]++
+		}
+		for pi := 0; pi < np; pi++ {
+			if n[pi] == 0 {
+				continue
+			}
+			mn := sum[pi] / n[pi]
+			sd := math.Sqrt(math.Max(ssq[pi]/n[pi]-mn*mn, 0))
+			dwt := math.NaN()
+			if prv != nil {
+				dwt = dsum[pi] / n[pi]
+			}
+			ss.LogWtPool(ss.WtPoolLog, pj.Name(), sess, point, side, pi, mn, sd, dwt)
+		}
+	}
+}
+
+// WtItems logs, for each item, the mean weight change from prv of its TopK
+// synapses by coactivity, and of all synapses
+func (ss *Sim) WtItems(pnm string, sess int, point string, wts, prv []float32, nr, ns int, spats, rpats map[int][]float64) {
+	all, nall := 0.0, 0
+	for i, w := range wts {
+		if !math.IsNaN(float64(w)) {
+			all += float64(w - prv[i])
+			nall++
+		}
+	}
+	if nall > 0 {
+		all /= float64(nall)
+	}
+	items := make([]int, 0, len(spats))
+	for it := range spats {
+		if _, ok := rpats[it]; ok {
+			items = append(items, it)
+		}
+	}
+	sort.Ints(items)
+	type syn struct {
+		idx   int
+		coact float64
+	}
+	for _, it := range items {
+		sp, rp := spats[it], rpats[it]
+		var syns []syn
+		for ri := 0; ri < nr; ri++ {
+			if rp[ri] <= 0 {
+				continue
+			}
+			for si := 0; si < ns; si++ {
+				i := ri*ns + si
+				if sp[si] <= 0 || math.IsNaN(float64(wts[i])) {
+					continue
+				}
+				syns = append(syns, syn{i, sp[si] * rp[ri]})
+			}
+		}
+		sort.Slice(syns, func(a, b int) bool { return syns[a].coact > syns[b].coact })
+		if len(syns) > ss.WtSnap.TopK {
+			syns = syns[:ss.WtSnap.TopK]
+		}
+		topk := math.NaN()
+		if len(syns) > 0 {
+			topk = 0
+			for _, sy := range syns {
+				topk += float64(wts[sy.idx] - prv[sy.idx])
+			}
+			topk /= float64(len(syns))
+		}
+		ss.LogWtItem(ss.WtItemLog, pnm, sess, point, it, len(syns), topk, all)
+	}
+}
+
+// WtFingerprints computes the weight fingerprint of each item, and logs
+// their same-item and different-item similarity with those of this and each
+// earlier snapshot of the projection
+func (ss *Sim) WtFingerprints(pnm string, sess int, point string, wts []float32, nr, ns int, spats map[int][]float64) {
+	fps := &WtFPSet{Sess: sess, Point: point, Items: map[int][]float64{}}
+	for it, sp := range spats {
+		fp := make([]float64, nr)
+		for ri := 0; ri < nr; ri++ {
+			sum, n := 0.0, 0
+			for si := 0; si < ns; si++ {
+				w := wts[ri*ns+si]
+				if sp[si] > ss.WtSnap.ActThr && !math.IsNaN(float64(w)) {
+					sum += float64(w)
+					n++
+				}
+			}
+			if n > 0 {
+				fp[ri] = sum / float64(n)
+			}
+		}
+		fps.Items[it] = fp
+	}
+	ss.WtFPs[pnm] = append(ss.WtFPs[pnm], fps)
+	for _, efp := range ss.WtFPs[pnm] {
+		same, diff := 0.0, 0.0
+		nsm, nd := 0, 0
+		for i1, a := range efp.Items {
+			for i2, b := range fps.Items {
+				if efp == fps && i2 <= i1 {
+					continue
+				}
+				sim := metric.Correlation64(a, b)
+				if i1 == i2 {
+					same += sim
+					nsm++
+				} else {
+					diff += sim
+					nd++
+				}
+			}
+		}
+		if nsm > 0 {
+			same /= float64(nsm)
+		}
+		if nd > 0 {
+			diff /= float64(nd)
+		}
+		ss.LogWtSim(ss.WtSimLog, pnm, efp, fps, same, diff, nsm, nd)
+	}
+}
+
+//////////////////////////////////////////////
+//  WtSnap files
+
+// LogWtSnap writes one weight snapshot of projection pnm to its file
+func (ss *Sim) LogWtSnap(pnm string, sess int, point string, wts []float32, nr, ns int) {
+	fl := ss.WtSnapFiles[pnm]
+	if fl == nil {
+		return
+	}
+	if ss.WtSnapTbls == nil {
+		ss.WtSnapTbls = map[string]*etable.Table{}
+		ss.WtSnapHdrs = map[string]bool{}
+	}
+	dt, ok := ss.WtSnapTbls[pnm]
+	if !ok {
+		dt = &etable.Table{}
+		ss.ConfigWtSnapTbl(dt, pnm, nr, ns)
+		ss.WtSnapTbls[pnm] = dt
+	}
+	dt.SetCellFloat("Run", 0, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Sess", 0, float64(sess))
+	dt.SetCellString("Point", 0, point)
+	copy(dt.CellTensor("Wt", 0).(*etensor.Float32).Values, wts)
+
+	if !ss.WtSnapHdrs[pnm] {
+		dt.WriteCSVHeaders(fl, etable.Tab)
+		ss.WtSnapHdrs[pnm] = true
+	}
+	dt.WriteCSVRow(fl, 0, etable.Tab)
+}
+
+// ConfigWtSnapTbl configures the one-row table for the snapshots of projection pnm
+func (ss *Sim) ConfigWtSnapTbl(dt *etable.Table, pnm string, nr, ns int) {
+	dt.SetMetaData("name", "WtSnap_"+pnm)
+	dt.SetMetaData("desc", "Weight snapshot of "+pnm+": Recv x Send weights, NaN = no synapse")
+	dt.SetMetaData("read-only", "true")
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Sess", etensor.INT64, nil, nil},
+		{"Point", etensor.STRING, nil, nil},
+		{"Wt", etensor.FLOAT32, []int{nr, ns}, []string{"Recv", "Send"}},
+	}
+	dt.SetFromSchema(sch, 1)
+}
+
+//////////////////////////////////////////////
+//  WtPoolLog
+
+// LogWtPool adds the weight stats of one pool to the WtPoolLog
+func (ss *Sim) LogWtPool(dt *etable.Table, pnm string, sess int, point, side string, pool int, wt, sd, dwt float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellString("Prjn", row, pnm)
+	dt.SetCellFloat("Sess", row, float64(sess))
+	dt.SetCellString("Point", row, point)
+	dt.SetCellString("Side", row, side)
+	dt.SetCellFloat("Pool", row, float64(pool))
+	dt.SetCellFloat("Wt", row, wt)
+	dt.SetCellFloat("WtSD", row, sd)
+	dt.SetCellFloat("DWt", row, dwt)
+
+	if ss.WtPoolPlot != nil {
+		ss.WtPoolPlot.GoUpdate()
+	}
+	if ss.WtPoolFile != nil {
+		if !ss.WtPoolHdrs {
+			dt.WriteCSVHeaders(ss.WtPoolFile, etable.Tab)
+			ss.WtPoolHdrs = true
+		}
+		dt.WriteCSVRow(ss.WtPoolFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigWtPoolLog(dt *etable.Table) {
+	dt.SetMetaData("name", "WtPoolLog")
+	dt.SetMetaData("desc", "Weight snapshot stats per pool of the sending and receiving layers: mean, SD, and mean change (DWt) from the previous snapshot")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Sess", etensor.INT64, nil, nil},
+		{"Point", etensor.STRING, nil, nil},
+		{"Side", etensor.STRING, nil, nil},
+		{"Pool", etensor.INT64, nil, nil},
+		{"Wt", etensor.FLOAT64, nil, nil},
+		{"WtSD", etensor.FLOAT64, nil, nil},
+		{"DWt", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigWtPoolPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Weight Pool Plot"
+	plt.Params.XAxisCol = "Pool"
+	plt.Params.LegendCol = "Sess"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Prjn", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Point", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Side", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Pool", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Wt", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("WtSD", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("DWt", eplot.On, eplot.FloatMin, 0, eplot.FloatMax, 0)
+	return plt
+}
+
+//////////////////////////////////////////////
+//  WtItemLog
+
+// LogWtItem adds the weight change of one item to the WtItemLog
+func (ss *Sim) LogWtItem(dt *etable.Table, pnm string, sess int, point string, item, n int, topk, all float64) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellString("Prjn", row, pnm)
+	dt.SetCellFloat("Sess", row, float64(sess))
+	dt.SetCellString("Point", row, point)
+	dt.SetCellFloat("Item", row, float64(item))
+	dt.SetCellFloat("NTopK", row, float64(n))
+	dt.SetCellFloat("TopKDWt", row, topk)
+	dt.SetCellFloat("AllDWt", row, all)
+
+	if ss.WtItemPlot != nil {
+		ss.WtItemPlot.GoUpdate()
+	}
+	if ss.WtItemFile != nil {
+		if !ss.WtItemHdrs {
+			dt.WriteCSVHeaders(ss.WtItemFile, etable.Tab)
+			ss.WtItemHdrs = true
+		}
+		dt.WriteCSVRow(ss.WtItemFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigWtItemLog(dt *etable.Table) {
+	dt.SetMetaData("name", "WtItemLog")
+	dt.SetMetaData("desc", "Mean weight change from the previous snapshot of the TopK synapses of each item (by study coactivity), and of all synapses")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Sess", etensor.INT64, nil, nil},
+		{"Point", etensor.STRING, nil, nil},
+		{"Item", etensor.INT64, nil, nil},
+		{"NTopK", etensor.INT64, nil, nil},
+		{"TopKDWt", etensor.FLOAT64, nil, nil},
+		{"AllDWt", etensor.FLOAT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigWtItemPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Weight Item Plot"
+	plt.Params.XAxisCol = "Item"
+	plt.Params.LegendCol = "Sess"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Prjn", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Point", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Item", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NTopK", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("TopKDWt", eplot.On, eplot.FloatMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("AllDWt", eplot.On, eplot.FloatMin, 0, eplot.FloatMax, 0)
+	return plt
+}
+
+//////////////////////////////////////////////
+//  WtSimLog
+
+// LogWtSim adds the item fingerprint similarities of two snapshots to the WtSimLog
+func (ss *Sim) LogWtSim(dt *etable.Table, pnm string, fp1, fp2 *WtFPSet, same, diff float64, ns, nd int) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellString("Prjn", row, pnm)
+	dt.SetCellFloat("Sess1", row, float64(fp1.Sess))
+	dt.SetCellString("Point1", row, fp1.Point)
+	dt.SetCellFloat("Sess2", row, float64(fp2.Sess))
+	dt.SetCellString("Point2", row, fp2.Point)
+	dt.SetCellFloat("SameSim", row, same)
+	dt.SetCellFloat("DiffSim", row, diff)
+	dt.SetCellFloat("NSame", row, float64(ns))
+	dt.SetCellFloat("NDiff", row, float64(nd))
+
+	if ss.WtSimPlot != nil {
+		ss.WtSimPlot.GoUpdate()
+	}
+	if ss.WtSimFile != nil {
+		if !ss.WtSimHdrs {
+			dt.WriteCSVHeaders(ss.WtSimFile, etable.Tab)
+			ss.WtSimHdrs = true
+		}
+		dt.WriteCSVRow(ss.WtSimFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigWtSimLog(dt *etable.Table) {
+	dt.SetMetaData("name", "WtSimLog")
+	dt.SetMetaData("desc", "Same-item and different-item correlation of item weight fingerprints between pairs of weight snapshots")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Sess1", etensor.INT64, nil, nil},
+		{"Point1", etensor.STRING, nil, nil},
+		{"Sess2", etensor.INT64, nil, nil},
+		{"Point2", etensor.STRING, nil, nil},
+		{"SameSim", etensor.FLOAT64, nil, nil},
+		{"DiffSim", etensor.FLOAT64, nil, nil},
+		{"NSame", etensor.INT64, nil, nil},
+		{"NDiff", etensor.INT64, nil, nil},
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigWtSimPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hippocampus Weight Similarity Plot"
+	plt.Params.XAxisCol = "Sess2"
+	plt.Params.LegendCol = "Sess1"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Prjn", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess1", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Point1", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Sess2", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Point2", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("SameSim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("DiffSim", eplot.On, eplot.FixMin, -1, eplot.FixMax, 1)
+	plt.SetColParams("NSame", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("NDiff", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	return plt
+}